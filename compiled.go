@@ -0,0 +1,559 @@
+package graphs
+
+import (
+	"container/heap"
+	"fmt"
+	"iter"
+	"slices"
+
+	"github.com/moznion/go-optional"
+)
+
+// CompiledGraph is a read-only form of a Graph optimized for queries. Nodes and
+// ports are interned as integers, connections are stored as compressed
+// adjacency lists and visited nodes are tracked in bitsets.
+//
+// A CompiledGraph does not follow changes made to the Graph after compiling,
+// including changes of its occupancy. The nodes referenced in found paths are the nodes
+// of the original Graph.
+type CompiledGraph[O, P comparable] struct {
+	nodes     []*Node[O, P]
+	nodeIds   map[O]int32
+	occupancy *Occupancy[O, P]
+
+	// A state is a node together with one of its ports.
+	states     map[portKey[O, P]]int32
+	stateNode  []int32
+	statePort  []P
+	outerStart []int32 // Outer connections leaving state i are outerStart[i]:outerStart[i+1]
+	outerState []int32 // State the connection leads to
+	outerConn  []*Connection[O, P]
+	innerStart []int32 // Inner transitions from state i are innerStart[i]:innerStart[i+1]
+	innerState []int32 // State the transition leads to
+}
+
+func (graph *Graph[O, P]) Compile() *CompiledGraph[O, P] {
+	compiled := &CompiledGraph[O, P]{
+		nodeIds:   map[O]int32{},
+		occupancy: graph.occupancy.clone(),
+		states:    map[portKey[O, P]]int32{},
+	}
+
+	addNode := func(node *Node[O, P]) {
+		if _, ok := compiled.nodeIds[node.id]; !ok {
+			compiled.nodeIds[node.id] = int32(len(compiled.nodes))
+			compiled.nodes = append(compiled.nodes, node)
+		}
+	}
+
+	addState := func(node *Node[O, P], port P) int32 {
+		key := portKey[O, P]{node.id, port}
+
+		state, ok := compiled.states[key]
+		if !ok {
+			state = int32(len(compiled.stateNode))
+			compiled.states[key] = state
+			compiled.stateNode = append(compiled.stateNode, compiled.nodeIds[node.id])
+			compiled.statePort = append(compiled.statePort, port)
+		}
+
+		return state
+	}
+
+	for _, id := range graph.order {
+		addNode(graph.nodes[id])
+	}
+
+	for _, connection := range graph.connections {
+		addNode(connection.FromNode)
+		addNode(connection.ToNode)
+	}
+
+	for _, node := range compiled.nodes {
+		for from, to := range node.InnerConnections() {
+			addState(node, from)
+			addState(node, to)
+		}
+	}
+
+	for _, connection := range graph.connections {
+		addState(connection.FromNode, connection.FromPort)
+		addState(connection.ToNode, connection.ToPort)
+	}
+
+	count := len(compiled.stateNode)
+	compiled.outerStart = make([]int32, count+1)
+	compiled.innerStart = make([]int32, count+1)
+
+	for state := range count {
+		node := compiled.nodes[compiled.stateNode[state]]
+		port := compiled.statePort[state]
+
+		compiled.outerStart[state] = int32(len(compiled.outerState))
+		for _, connection := range graph.outgoing[portKey[O, P]{node.id, port}] {
+			compiled.outerState = append(compiled.outerState, compiled.states[portKey[O, P]{connection.ToNode.id, connection.ToPort}])
+			compiled.outerConn = append(compiled.outerConn, connection)
+		}
+
+		compiled.innerStart[state] = int32(len(compiled.innerState))
+		for _, next := range node.Next(port) {
+			compiled.innerState = append(compiled.innerState, compiled.states[portKey[O, P]{node.id, next}])
+		}
+	}
+
+	compiled.outerStart[count] = int32(len(compiled.outerState))
+	compiled.innerStart[count] = int32(len(compiled.innerState))
+
+	return compiled
+}
+
+func (compiled *CompiledGraph[O, P]) node(id O) (*Node[O, P], bool) {
+	idx, ok := compiled.nodeIds[id]
+	if !ok {
+		return nil, false
+	}
+
+	return compiled.nodes[idx], true
+}
+
+func (compiled *CompiledGraph[O, P]) Find(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P) [][]*PathSegment[O, P] {
+	return slices.Collect(compiled.Paths(fromNode, fromPort, toNode, toPort))
+}
+
+func (compiled *CompiledGraph[O, P]) FindRef(fromRef O, fromPort P, toRef O, toPort P) ([][]*PathSegment[O, P], error) {
+	paths, err := compiled.PathsRef(fromRef, fromPort, toRef, toPort)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Collect(paths), nil
+}
+
+// Paths yields the same paths in the same order as Graph.Paths.
+func (compiled *CompiledGraph[O, P]) Paths(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P) iter.Seq[[]*PathSegment[O, P]] {
+	return func(yield func([]*PathSegment[O, P]) bool) {
+		search := &compiledSearch[O, P]{
+			compiled: compiled,
+			visited:  make(bitset, (len(compiled.nodes)+63)/64),
+			from:     fromNode,
+			toNode:   -1,
+			toPort:   toPort,
+			yield:    yield,
+		}
+
+		if idx, ok := compiled.nodeIds[toNode.id]; ok {
+			search.toNode = idx
+		}
+
+		fromIdx, ok := compiled.nodeIds[fromNode.id]
+		if !ok {
+			// The start node is unknown, so the only path is the one that
+			// starts and ends right there.
+			if fromNode.Equals(toNode) && fromPort == toPort {
+				var entry P
+				yield([]*PathSegment[O, P]{{optional.Some(entry), fromNode, optional.Some(fromPort), nil}})
+			}
+			return
+		}
+
+		state, ok := compiled.states[portKey[O, P]{fromNode.id, fromPort}]
+		if !ok {
+			state = -1
+		}
+
+		var entry P
+		search.dfs(fromIdx, state, fromPort, entry, nil)
+	}
+}
+
+func (compiled *CompiledGraph[O, P]) PathsRef(fromRef O, fromPort P, toRef O, toPort P) (iter.Seq[[]*PathSegment[O, P]], error) {
+	fromNode, ok := compiled.node(fromRef)
+	if !ok {
+		return nil, fmt.Errorf("graph: from ref %v not found and thus cannot find paths", fromRef)
+	}
+
+	toNode, ok := compiled.node(toRef)
+	if !ok {
+		return nil, fmt.Errorf("graph: to ref %v not found and thus cannot find paths", toRef)
+	}
+
+	return compiled.Paths(fromNode, fromPort, toNode, toPort), nil
+}
+
+// ShortestPath works like Graph.ShortestPath.
+func (compiled *CompiledGraph[O, P]) ShortestPath(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P, costs *Costs[O, P]) ([]*PathSegment[O, P], float64, error) {
+	var entry P
+	hops, cumulative, err := compiled.dijkstra(hop[O, P]{fromNode, entry, fromPort, nil}, portKey[O, P]{toNode.id, toPort}, costs, nil, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return hopsToPath(hops), cumulative[len(cumulative)-1], nil
+}
+
+func (compiled *CompiledGraph[O, P]) ShortestPathRef(fromRef O, fromPort P, toRef O, toPort P, costs *Costs[O, P]) ([]*PathSegment[O, P], float64, error) {
+	fromNode, ok := compiled.node(fromRef)
+	if !ok {
+		return nil, 0, fmt.Errorf("graph: from ref %v not found and thus cannot find paths", fromRef)
+	}
+
+	toNode, ok := compiled.node(toRef)
+	if !ok {
+		return nil, 0, fmt.Errorf("graph: to ref %v not found and thus cannot find paths", toRef)
+	}
+
+	return compiled.ShortestPath(fromNode, fromPort, toNode, toPort, costs)
+}
+
+// KShortestPaths works like Graph.KShortestPaths.
+func (compiled *CompiledGraph[O, P]) KShortestPaths(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P, k int, costs *Costs[O, P]) ([][]*PathSegment[O, P], []float64, error) {
+	var entry P
+	goal := portKey[O, P]{toNode.id, toPort}
+
+	return yen(hop[O, P]{fromNode, entry, fromPort, nil}, k, func(start hop[O, P], bannedNodes map[O]bool, bannedEdges map[hopEdge[O, P]]bool) ([]hop[O, P], []float64, error) {
+		return compiled.dijkstra(start, goal, costs, bannedNodes, bannedEdges)
+	})
+}
+
+func (compiled *CompiledGraph[O, P]) KShortestPathsRef(fromRef O, fromPort P, toRef O, toPort P, k int, costs *Costs[O, P]) ([][]*PathSegment[O, P], []float64, error) {
+	fromNode, ok := compiled.node(fromRef)
+	if !ok {
+		return nil, nil, fmt.Errorf("graph: from ref %v not found and thus cannot find paths", fromRef)
+	}
+
+	toNode, ok := compiled.node(toRef)
+	if !ok {
+		return nil, nil, fmt.Errorf("graph: to ref %v not found and thus cannot find paths", toRef)
+	}
+
+	return compiled.KShortestPaths(fromNode, fromPort, toNode, toPort, k, costs)
+}
+
+// dijkstra works like Graph.dijkstra.
+func (compiled *CompiledGraph[O, P]) dijkstra(start hop[O, P], goal portKey[O, P], costs *Costs[O, P], bannedNodes map[O]bool, bannedEdges map[hopEdge[O, P]]bool) ([]hop[O, P], []float64, error) {
+	if !compiled.occupancy.passable(start.node.id, start.entry, start.exit, true) {
+		return nil, nil, ErrNoPath
+	}
+
+	hops, cumulative, err := compiled.cheapestHops(start, goal, costs, bannedNodes, bannedEdges)
+	if err != nil || !reentersNode(hops) {
+		return hops, cumulative, err
+	}
+
+	return looplessHops(start, goal, func(from hop[O, P], visit func(next hop[O, P], weight float64)) error {
+		return compiled.relax(from, costs, bannedNodes, bannedEdges, visit)
+	})
+}
+
+// relax works like Graph.relax.
+func (compiled *CompiledGraph[O, P]) relax(from hop[O, P], costs *Costs[O, P], bannedNodes map[O]bool, bannedEdges map[hopEdge[O, P]]bool, visit func(next hop[O, P], weight float64)) error {
+	state, ok := compiled.states[from.key()]
+	if !ok {
+		return nil
+	}
+
+	for e := compiled.outerStart[state]; e < compiled.outerStart[state+1]; e++ {
+		arrival := compiled.outerState[e]
+		node := compiled.nodes[compiled.stateNode[arrival]]
+		if bannedNodes[node.id] {
+			continue
+		}
+
+		connection := compiled.outerConn[e]
+		for i := compiled.innerStart[arrival]; i < compiled.innerStart[arrival+1]; i++ {
+			port := compiled.statePort[compiled.innerState[i]]
+
+			next := hop[O, P]{node, connection.ToPort, port, connection}
+			if bannedEdges[newHopEdge(from, next)] || !compiled.occupancy.passable(node.id, connection.ToPort, port, false) {
+				continue
+			}
+
+			weight := costs.connection(connection) + costs.inner(node, connection.ToPort, port)
+			if weight < 0 {
+				return fmt.Errorf("graph: negative cost %v for %s -> %v", weight, connection, port)
+			}
+
+			visit(next, weight)
+		}
+	}
+
+	return nil
+}
+
+// cheapestHops works like the function of the same name, but on states.
+func (compiled *CompiledGraph[O, P]) cheapestHops(start hop[O, P], goal portKey[O, P], costs *Costs[O, P], bannedNodes map[O]bool, bannedEdges map[hopEdge[O, P]]bool) ([]hop[O, P], []float64, error) {
+
+	startState, ok := compiled.states[start.key()]
+	if !ok {
+		if start.key() == goal {
+			return []hop[O, P]{start}, []float64{0}, nil
+		}
+
+		return nil, nil, ErrNoPath
+	}
+
+	goalState, ok := compiled.states[goal]
+	if !ok {
+		return nil, nil, ErrNoPath
+	}
+
+	banned := make(bitset, (len(compiled.nodes)+63)/64)
+	for id := range bannedNodes {
+		if idx, ok := compiled.nodeIds[id]; ok {
+			banned.set(idx)
+		}
+	}
+
+	count := len(compiled.stateNode)
+	cost := make([]float64, count)
+	previous := make([]int32, count)
+	entry := make([]P, count)
+	via := make([]*Connection[O, P], count)
+	labeled := make(bitset, (count+63)/64)
+	done := make(bitset, (count+63)/64)
+
+	labeled.set(startState)
+	previous[startState] = -1
+	entry[startState] = start.entry
+	via[startState] = start.via
+
+	queue := &compiledQueue{{startState, 0, 0}}
+	seq := 1
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(compiledItem)
+
+		state := item.state
+		if done.has(state) || item.cost > cost[state] {
+			continue
+		}
+
+		done.set(state)
+		if state == goalState {
+			return compiled.unwind(state, start, cost, previous, entry, via)
+		}
+
+		for e := compiled.outerStart[state]; e < compiled.outerStart[state+1]; e++ {
+			arrival := compiled.outerState[e]
+			node := compiled.stateNode[arrival]
+			if banned.has(node) {
+				continue
+			}
+
+			connection := compiled.outerConn[e]
+			for i := compiled.innerStart[arrival]; i < compiled.innerStart[arrival+1]; i++ {
+				next := compiled.innerState[i]
+
+				if !compiled.occupancy.passable(compiled.nodes[node].id, connection.ToPort, compiled.statePort[next], false) {
+					continue
+				}
+
+				if len(bannedEdges) > 0 {
+					from := hop[O, P]{compiled.nodes[compiled.stateNode[state]], entry[state], compiled.statePort[state], nil}
+					to := hop[O, P]{compiled.nodes[node], connection.ToPort, compiled.statePort[next], nil}
+					if bannedEdges[newHopEdge(from, to)] {
+						continue
+					}
+				}
+
+				weight := costs.connection(connection) + costs.inner(compiled.nodes[node], connection.ToPort, compiled.statePort[next])
+				if weight < 0 {
+					return nil, nil, fmt.Errorf("graph: negative cost %v for %s -> %v", weight, connection, compiled.statePort[next])
+				}
+
+				total := cost[state] + weight
+				if labeled.has(next) && (done.has(next) || cost[next] <= total) {
+					continue
+				}
+
+				labeled.set(next)
+				cost[next] = total
+				previous[next] = state
+				entry[next] = connection.ToPort
+				via[next] = connection
+				heap.Push(queue, compiledItem{next, total, seq})
+				seq++
+			}
+		}
+	}
+
+	return nil, nil, ErrNoPath
+}
+
+func (compiled *CompiledGraph[O, P]) unwind(state int32, start hop[O, P], cost []float64, previous []int32, entry []P, via []*Connection[O, P]) ([]hop[O, P], []float64, error) {
+	hops := []hop[O, P]{}
+	cumulative := []float64{}
+
+	for s := state; s >= 0; s = previous[s] {
+		hops = append(hops, hop[O, P]{compiled.nodes[compiled.stateNode[s]], entry[s], compiled.statePort[s], via[s]})
+		cumulative = append(cumulative, cost[s])
+	}
+
+	slices.Reverse(hops)
+	slices.Reverse(cumulative)
+
+	hops[0].node = start.node
+	return hops, cumulative, nil
+}
+
+type compiledSearch[O, P comparable] struct {
+	compiled *CompiledGraph[O, P]
+	visited  bitset
+	from     *Node[O, P]
+	toNode   int32
+	toPort   P
+	path     []compiledSegment[O, P]
+	yield    func([]*PathSegment[O, P]) bool
+	stopped  bool
+}
+
+type compiledSegment[O, P comparable] struct {
+	node  int32
+	entry P
+	exit  P
+	via   *Connection[O, P]
+}
+
+// dfs mirrors Graph.dfsFind: node has been entered via entry and is left via
+// the port of state, which is -1 if nothing is connected to that port.
+func (search *compiledSearch[O, P]) dfs(node, state int32, port, entry P, via *Connection[O, P]) {
+	if search.visited.has(node) {
+		return
+	}
+
+	if !search.compiled.occupancy.passable(search.compiled.nodes[node].id, entry, port, len(search.path) == 0) {
+		return
+	}
+
+	search.visited.set(node)
+	search.path = append(search.path, compiledSegment[O, P]{node, entry, port, via})
+
+	if node == search.toNode && port == search.toPort {
+		search.found(false)
+		search.visited.clear(node)
+		return
+	}
+
+	compiled := search.compiled
+	if state >= 0 {
+		for e := compiled.outerStart[state]; e < compiled.outerStart[state+1]; e++ {
+			arrival := compiled.outerState[e]
+			next := compiled.stateNode[arrival]
+
+			for i := compiled.innerStart[arrival]; i < compiled.innerStart[arrival+1]; i++ {
+				exit := compiled.innerState[i]
+
+				if node == search.toNode && compiled.statePort[exit] == search.toPort {
+					search.found(true)
+					search.visited.clear(node)
+					return
+				}
+
+				search.dfs(next, exit, compiled.statePort[exit], compiled.statePort[arrival], compiled.outerConn[e])
+
+				if search.stopped {
+					return
+				}
+			}
+		}
+	}
+
+	search.path = search.path[:len(search.path)-1]
+	search.visited.clear(node)
+}
+
+func (search *compiledSearch[O, P]) found(clearEnds bool) {
+	// Allocate all segments of a path at once, as building paths dominates
+	// searches with many results.
+	segments := make([]PathSegment[O, P], len(search.path))
+	path := make([]*PathSegment[O, P], len(search.path))
+
+	for i, segment := range search.path {
+		segments[i] = PathSegment[O, P]{optional.Some(segment.entry), search.compiled.nodes[segment.node], optional.Some(segment.exit), segment.via}
+		path[i] = &segments[i]
+	}
+
+	path[0].Middle = search.from
+
+	if clearEnds {
+		path[0].Left = optional.None[P]()
+		path[len(path)-1].Right = optional.None[P]()
+	}
+
+	if !search.yield(path) {
+		search.stopped = true
+	}
+
+	search.path = search.path[:len(search.path)-1]
+}
+
+type bitset []uint64
+
+func (set bitset) has(i int32) bool {
+	return int(i/64) < len(set) && set[i/64]&(1<<(i%64)) != 0
+}
+
+func (set bitset) set(i int32) {
+	set[i/64] |= 1 << (i % 64)
+}
+
+func (set bitset) clear(i int32) {
+	set[i/64] &^= 1 << (i % 64)
+}
+
+// with returns a copy of the set that also contains i.
+func (set bitset) with(i int32) bitset {
+	grown := make(bitset, max(len(set), int(i/64)+1))
+	copy(grown, set)
+	grown.set(i)
+	return grown
+}
+
+func (set bitset) subsetOf(other bitset) bool {
+	for i, word := range set {
+		if i < len(other) {
+			word &^= other[i]
+		}
+
+		if word != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+type compiledItem struct {
+	state int32
+	cost  float64
+	seq   int
+}
+
+type compiledQueue []compiledItem
+
+func (queue compiledQueue) Len() int {
+	return len(queue)
+}
+
+func (queue compiledQueue) Less(i, j int) bool {
+	if queue[i].cost != queue[j].cost {
+		return queue[i].cost < queue[j].cost
+	}
+
+	return queue[i].seq < queue[j].seq
+}
+
+func (queue compiledQueue) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+}
+
+func (queue *compiledQueue) Push(x any) {
+	*queue = append(*queue, x.(compiledItem))
+}
+
+func (queue *compiledQueue) Pop() any {
+	old := *queue
+	item := old[len(old)-1]
+	*queue = old[:len(old)-1]
+	return item
+}
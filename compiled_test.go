@@ -0,0 +1,182 @@
+package graphs_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+// MakeStationGraph builds a line of railway points. Each point's main port
+// leads to the next point, while its diversion leads into a siding of three
+// nodes ending in a buffer stop. Every siding has to be searched, but there is
+// only one path from the first to the last point.
+func MakeStationGraph(points int) *graphs.Graph[string, string] {
+	graph := graphs.NewGraph[string, string]()
+
+	for i := range points {
+		point := graphs.NewNode[string, string](fmt.Sprintf("W%d", i))
+		point.ConnectBi("head", "main")
+		point.ConnectBi("head", "diversion")
+		graph.AddNode(point)
+
+		for j := range 3 {
+			track := graphs.NewNode[string, string](fmt.Sprintf("W%d-%d", i, j))
+			track.ConnectBi("a", "b")
+			graph.AddNode(track)
+		}
+
+		graph.ConnectRefBi(fmt.Sprintf("W%d", i), "diversion", fmt.Sprintf("W%d-0", i), "a")
+		graph.ConnectRefBi(fmt.Sprintf("W%d-0", i), "b", fmt.Sprintf("W%d-1", i), "a")
+		graph.ConnectRefBi(fmt.Sprintf("W%d-1", i), "b", fmt.Sprintf("W%d-2", i), "a")
+
+		if i > 0 {
+			graph.ConnectRefBi(fmt.Sprintf("W%d", i-1), "main", fmt.Sprintf("W%d", i), "head")
+		}
+	}
+
+	return graph
+}
+
+func TestCompiledFind(t *testing.T) {
+	tests := []struct {
+		graph    *graphs.Graph[string, string]
+		fromRef  string
+		fromPort string
+		toRef    string
+		toPort   string
+	}{
+		{MakeFanOutGraph(), "S", "out", "C", "out"},
+		{MakeFanOutGraph(), "S", "out", "B", "out"},
+		{MakeLadderGraph(6), "S", "out", "T", "out"},
+		{MakeLadderGraph(6), "T", "in", "U3", "in"},
+		{MakeStationGraph(20), "W0", "main", "W19", "diversion"},
+		{MakeStationGraph(20), "W19", "head", "W0", "head"},
+		{MakeStationGraph(20), "W5-2", "a", "W3", "head"},
+	}
+
+	for _, test := range tests {
+		expected, _ := test.graph.FindRef(test.fromRef, test.fromPort, test.toRef, test.toPort)
+		actual, err := test.graph.Compile().FindRef(test.fromRef, test.fromPort, test.toRef, test.toPort)
+		if err != nil {
+			t.Fatalf("error when finding paths: %s", err)
+		}
+
+		if len(expected) == 0 {
+			t.Fatalf("expected %s.%s -> %s.%s to have paths", test.fromRef, test.fromPort, test.toRef, test.toPort)
+		}
+
+		if fmt.Sprint(expected) != fmt.Sprint(actual) {
+			t.Fatalf("expected %v, but got %v", expected, actual)
+		}
+	}
+}
+
+func TestCompiledFindStation(t *testing.T) {
+	graph := MakeStationGraph(20)
+	compiled := graph.Compile()
+
+	expected, _ := graph.FindRef("W0", "main", "W19", "main")
+	actual, err := compiled.FindRef("W0", "main", "W19", "main")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(actual) != 1 || len(actual[0]) != 20 {
+		t.Fatalf("expected 1 path of 20 points, but got %v", actual)
+	}
+
+	if fmt.Sprint(expected) != fmt.Sprint(actual) {
+		t.Fatalf("expected %v, but got %v", expected, actual)
+	}
+}
+
+func TestCompiledFindGraph(t *testing.T) {
+	graph := MakeGraph()
+	compiled := graph.Compile()
+
+	for _, query := range [][4]any{{1, "b", 4, "b"}, {1, "b", 6, "b"}, {6, "b", 1, "a"}, {1, "a", 6, "b"}} {
+		expected, _ := graph.FindRef(query[0].(int), query[1].(string), query[2].(int), query[3].(string))
+		actual, _ := compiled.FindRef(query[0].(int), query[1].(string), query[2].(int), query[3].(string))
+
+		if fmt.Sprint(expected) != fmt.Sprint(actual) {
+			t.Fatalf("%v: expected %v, but got %v", query, expected, actual)
+		}
+	}
+}
+
+func TestCompiledShortestPaths(t *testing.T) {
+	graph := MakeGraph()
+	compiled := graph.Compile()
+
+	costs := &graphs.Costs[int, string]{
+		Connection: func(connection *graphs.Connection[int, string]) float64 {
+			if connection.ToNode.Id() == 3 {
+				return 10
+			}
+			return 1
+		},
+	}
+
+	expected, expectedCost, _ := graph.ShortestPathRef(1, "b", 6, "b", costs)
+	actual, actualCost, err := compiled.ShortestPathRef(1, "b", 6, "b", costs)
+	if err != nil {
+		t.Fatalf("error when finding shortest path: %s", err)
+	}
+
+	if fmt.Sprint(expected) != fmt.Sprint(actual) || expectedCost != actualCost {
+		t.Fatalf("expected %v with cost %v, but got %v with cost %v", expected, expectedCost, actual, actualCost)
+	}
+
+	expectedPaths, expectedCosts, _ := graph.KShortestPathsRef(6, "a", 1, "a", 5, costs)
+	actualPaths, actualCosts, err := compiled.KShortestPathsRef(6, "a", 1, "a", 5, costs)
+	if err != nil {
+		t.Fatalf("error when finding k shortest paths: %s", err)
+	}
+
+	if len(actualPaths) != 2 || fmt.Sprint(expectedPaths) != fmt.Sprint(actualPaths) || fmt.Sprint(expectedCosts) != fmt.Sprint(actualCosts) {
+		t.Fatalf("expected %v with costs %v, but got %v with costs %v", expectedPaths, expectedCosts, actualPaths, actualCosts)
+	}
+}
+
+func BenchmarkFindStation(b *testing.B) {
+	graph := MakeStationGraph(2500)
+
+	b.Run("Graph", func(b *testing.B) {
+		for b.Loop() {
+			graph.FindRef("W0", "main", "W2499", "main")
+		}
+	})
+
+	b.Run("Compiled", func(b *testing.B) {
+		compiled := graph.Compile()
+		for b.Loop() {
+			compiled.FindRef("W0", "main", "W2499", "main")
+		}
+	})
+}
+
+func BenchmarkShortestPathStation(b *testing.B) {
+	graph := MakeStationGraph(2500)
+
+	b.Run("Graph", func(b *testing.B) {
+		for b.Loop() {
+			graph.ShortestPathRef("W0", "main", "W2499", "main", nil)
+		}
+	})
+
+	b.Run("Compiled", func(b *testing.B) {
+		compiled := graph.Compile()
+		for b.Loop() {
+			compiled.ShortestPathRef("W0", "main", "W2499", "main", nil)
+		}
+	})
+}
+
+func BenchmarkCompile(b *testing.B) {
+	graph := MakeStationGraph(2500)
+
+	for b.Loop() {
+		graph.Compile()
+	}
+}
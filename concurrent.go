@@ -0,0 +1,75 @@
+package graphs
+
+import (
+	"context"
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// ConcurrentGraph makes a Graph safe for concurrent use. Readers search an
+// immutable snapshot and never block, while writers are serialized and publish
+// a modified copy of the graph once their changes are complete.
+//
+// Writing copies the whole graph, so this suits graphs that are searched much
+// more often than they are changed. The occupancy is not part of the snapshots:
+// all of them share a single occupancy that is changed in place without Update,
+// so searches always see the latest occupations.
+type ConcurrentGraph[O, P comparable] struct {
+	mutex     sync.Mutex
+	snapshot  atomic.Pointer[Graph[O, P]]
+	occupancy *Occupancy[O, P]
+}
+
+// NewConcurrentGraph wraps a copy of graph, so graph may still be changed
+// afterwards without affecting the concurrent graph.
+func NewConcurrentGraph[O, P comparable](graph *Graph[O, P]) *ConcurrentGraph[O, P] {
+	clone := graph.Clone()
+
+	concurrent := &ConcurrentGraph[O, P]{occupancy: clone.occupancy}
+	concurrent.snapshot.Store(clone)
+	return concurrent
+}
+
+// Occupancy returns the occupancy shared by all snapshots.
+func (concurrent *ConcurrentGraph[O, P]) Occupancy() *Occupancy[O, P] {
+	return concurrent.occupancy
+}
+
+// Snapshot returns the current state of the graph. It must not be changed.
+func (concurrent *ConcurrentGraph[O, P]) Snapshot() *Graph[O, P] {
+	return concurrent.snapshot.Load()
+}
+
+// Update applies fn to a copy of the current graph and publishes the copy if fn
+// succeeds. Searches running in the meantime keep using the previous snapshot.
+func (concurrent *ConcurrentGraph[O, P]) Update(fn func(graph *Graph[O, P]) error) error {
+	concurrent.mutex.Lock()
+	defer concurrent.mutex.Unlock()
+
+	graph := concurrent.snapshot.Load().Clone()
+	graph.occupancy = concurrent.occupancy
+
+	if err := fn(graph); err != nil {
+		return err
+	}
+
+	concurrent.snapshot.Store(graph)
+	return nil
+}
+
+func (concurrent *ConcurrentGraph[O, P]) FindRef(fromRef O, fromPort P, toRef O, toPort P) ([][]*PathSegment[O, P], error) {
+	return concurrent.Snapshot().FindRef(fromRef, fromPort, toRef, toPort)
+}
+
+func (concurrent *ConcurrentGraph[O, P]) FindRefContext(ctx context.Context, fromRef O, fromPort P, toRef O, toPort P, options FindOptions[O, P]) ([][]*PathSegment[O, P], FindStatus, error) {
+	return concurrent.Snapshot().FindRefContext(ctx, fromRef, fromPort, toRef, toPort, options)
+}
+
+func (concurrent *ConcurrentGraph[O, P]) PathsRef(fromRef O, fromPort P, toRef O, toPort P) (iter.Seq[[]*PathSegment[O, P]], error) {
+	return concurrent.Snapshot().PathsRef(fromRef, fromPort, toRef, toPort)
+}
+
+func (concurrent *ConcurrentGraph[O, P]) ShortestPathRef(fromRef O, fromPort P, toRef O, toPort P, costs *Costs[O, P]) ([]*PathSegment[O, P], float64, error) {
+	return concurrent.Snapshot().ShortestPathRef(fromRef, fromPort, toRef, toPort, costs)
+}
@@ -0,0 +1,175 @@
+package graphs_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+func TestConcurrentGraphSnapshot(t *testing.T) {
+	graph := MakeGraph()
+	concurrent := graphs.NewConcurrentGraph(graph)
+
+	graph.RemoveNode(3)
+
+	paths, err := concurrent.FindRef(1, "b", 6, "b")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 2 {
+		t.Fatalf("expected original graph not to affect concurrent graph, but got %v", paths)
+	}
+
+	snapshot := concurrent.Snapshot()
+	if err := concurrent.Update(func(g *graphs.Graph[int, string]) error { return g.RemoveNode(4) }); err != nil {
+		t.Fatalf("error when updating graph: %s", err)
+	}
+
+	if paths, _ := snapshot.FindRef(1, "b", 6, "b"); len(paths) != 2 {
+		t.Fatalf("expected old snapshot to be unchanged, but got %v", paths)
+	}
+
+	if paths, _ := concurrent.FindRef(1, "b", 6, "b"); len(paths) != 1 {
+		t.Fatalf("expected update to be visible, but got %v", paths)
+	}
+}
+
+func TestConcurrentGraphUpdateError(t *testing.T) {
+	concurrent := graphs.NewConcurrentGraph(MakeGraph())
+
+	err := concurrent.Update(func(g *graphs.Graph[int, string]) error {
+		g.RemoveNode(4)
+		return errors.New("failed")
+	})
+
+	if err == nil {
+		t.Fatalf("expected error from update")
+	}
+
+	if paths, _ := concurrent.FindRef(1, "b", 6, "b"); len(paths) != 2 {
+		t.Fatalf("expected failed update not to be published, but got %v", paths)
+	}
+}
+
+func TestConcurrentGraphRace(t *testing.T) {
+	concurrent := graphs.NewConcurrentGraph(MakeGraph())
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	errs := make(chan error, 8)
+
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				paths, err := concurrent.FindRef(1, "b", 6, "b")
+				if err != nil {
+					errs <- err
+					return
+				}
+
+				if len(paths) != 1 && len(paths) != 2 {
+					errs <- errors.New("inconsistent snapshot")
+					return
+				}
+			}
+		}()
+	}
+
+	for i := range 200 {
+		err := concurrent.Update(func(g *graphs.Graph[int, string]) error {
+			if i%2 == 0 {
+				return g.DisconnectRefBi(2, "e", 4, "a")
+			}
+			return g.ConnectRefBi(2, "e", 4, "a")
+		})
+
+		if err != nil {
+			t.Fatalf("error when updating graph: %s", err)
+		}
+	}
+
+	close(done)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("error when searching concurrently: %s", err)
+	}
+}
+
+func TestConcurrentGraphOccupancyRace(t *testing.T) {
+	graph := MakeGraph()
+	concurrent := graphs.NewConcurrentGraph(graph)
+	snapshot := concurrent.Snapshot()
+
+	var wg, ready sync.WaitGroup
+	done := make(chan struct{})
+	errs := make(chan error, 8)
+
+	for range 8 {
+		wg.Add(1)
+		ready.Add(1)
+		go func() {
+			defer wg.Done()
+			ready.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				if paths, _ := concurrent.FindRef(1, "b", 6, "b"); len(paths) != 1 && len(paths) != 2 {
+					errs <- fmt.Errorf("expected 1 or 2 paths, but got %v", paths)
+					return
+				}
+			}
+		}()
+	}
+
+	ready.Wait()
+	for i := range 200 {
+		if i%2 == 0 {
+			concurrent.Occupancy().OccupyNode(3)
+		} else {
+			concurrent.Occupancy().ReleaseNode(3)
+		}
+	}
+
+	close(done)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("error when searching concurrently: %s", err)
+	}
+
+	concurrent.Occupancy().OccupyNode(3)
+	concurrent.Update(func(g *graphs.Graph[int, string]) error {
+		return nil
+	})
+
+	if paths, _ := concurrent.FindRef(1, "b", 6, "b"); len(paths) != 1 {
+		t.Fatalf("expected occupation to outlast updates, but got %v", paths)
+	}
+
+	if paths, _ := snapshot.FindRef(1, "b", 6, "b"); len(paths) != 1 {
+		t.Fatalf("expected occupation to be visible in older snapshots, but got %v", paths)
+	}
+
+	if graph.Occupancy().NodeOccupied(3) {
+		t.Fatalf("expected occupancy of the wrapped graph to be unchanged")
+	}
+}
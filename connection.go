@@ -3,10 +3,21 @@ package graphs
 import "fmt"
 
 type Connection[O, P comparable] struct {
-	FromNode *Node[O, P]
-	FromPort P
-	ToNode   *Node[O, P]
-	ToPort   P
+	FromNode   *Node[O, P]
+	FromPort   P
+	ToNode     *Node[O, P]
+	ToPort     P
+	Attributes Attributes
+}
+
+// Attributes carry arbitrary data of a connection, like lengths, cable types or
+// capacities.
+type Attributes map[string]any
+
+// Attribute returns the attribute stored under key if it has type T.
+func Attribute[T any](attributes Attributes, key string) (T, bool) {
+	value, ok := attributes[key].(T)
+	return value, ok
 }
 
 func (connection *Connection[O, P]) IsSelf() bool {
@@ -17,10 +28,18 @@ func (connection *Connection[O, P]) EqualNodes() bool {
 	return connection.FromNode.Equals(connection.ToNode)
 }
 
+func (connection *Connection[O, P]) Equals(o *Connection[O, P]) bool {
+	return connection.FromNode.Equals(o.FromNode) && connection.FromPort == o.FromPort && connection.ToNode.Equals(o.ToNode) && connection.ToPort == o.ToPort
+}
+
 func (connection *Connection[O, P]) String() string {
 	return fmt.Sprintf("%v.%v -> %v.%v", connection.FromNode.id, connection.FromPort, connection.ToNode.id, connection.ToPort)
 }
 
 func NewConnection[O, P comparable](fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P) *Connection[O, P] {
-	return &Connection[O, P]{fromNode, fromPort, toNode, toPort}
+	return &Connection[O, P]{fromNode, fromPort, toNode, toPort, nil}
+}
+
+func NewConnectionWith[O, P comparable](fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P, attributes Attributes) *Connection[O, P] {
+	return &Connection[O, P]{fromNode, fromPort, toNode, toPort, attributes}
 }
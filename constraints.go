@@ -0,0 +1,91 @@
+package graphs
+
+import "slices"
+
+// PortRef references a port of a node by the node's ID.
+type PortRef[O, P comparable] struct {
+	Node O
+	Port P
+}
+
+// Constraints restrict the paths a search returns, see FindOptions. Paths must
+// pass the nodes in Via in the given order and must neither pass a node in
+// AvoidNodes nor use a port in AvoidPorts. The search skips branches violating
+// them right away instead of filtering the found paths.
+type Constraints[O, P comparable] struct {
+	Via        []O
+	AvoidNodes []O
+	AvoidPorts []PortRef[O, P]
+}
+
+// searchConstraints tracks the constraints during a single search.
+type searchConstraints[O, P comparable] struct {
+	via        []O
+	reached    int
+	avoidNodes map[O]bool
+	avoidPorts map[portKey[O, P]]bool
+}
+
+// newSearchConstraints returns nil without any constraints, which allows every
+// path.
+func newSearchConstraints[O, P comparable](constraints Constraints[O, P]) *searchConstraints[O, P] {
+	if len(constraints.Via) == 0 && len(constraints.AvoidNodes) == 0 && len(constraints.AvoidPorts) == 0 {
+		return nil
+	}
+
+	search := &searchConstraints[O, P]{
+		via:        constraints.Via,
+		avoidNodes: map[O]bool{},
+		avoidPorts: map[portKey[O, P]]bool{},
+	}
+
+	for _, id := range constraints.AvoidNodes {
+		search.avoidNodes[id] = true
+	}
+
+	for _, ref := range constraints.AvoidPorts {
+		search.avoidPorts[portKey[O, P]{ref.Node, ref.Port}] = true
+	}
+
+	return search
+}
+
+// allows reports whether a node may be passed from entry to exit. Passing a
+// via node before the ones preceding it is not allowed. The entry is ignored
+// for the first node of a path.
+func (search *searchConstraints[O, P]) allows(id O, entry P, exit P, first bool) bool {
+	if search == nil {
+		return true
+	}
+
+	if search.avoidNodes[id] || search.avoidPorts[portKey[O, P]{id, exit}] {
+		return false
+	}
+
+	if !first && search.avoidPorts[portKey[O, P]{id, entry}] {
+		return false
+	}
+
+	idx := slices.Index(search.via, id)
+	return idx < 0 || idx == search.reached
+}
+
+// enter records that a node has been added to the path and reports whether it
+// was the next via node. If so, leave must be called when it is removed again.
+func (search *searchConstraints[O, P]) enter(id O) bool {
+	if search == nil || search.reached >= len(search.via) || search.via[search.reached] != id {
+		return false
+	}
+
+	search.reached++
+	return true
+}
+
+func (search *searchConstraints[O, P]) leave() {
+	search.reached--
+}
+
+// complete reports whether all via nodes have been passed.
+func (search *searchConstraints[O, P]) complete() bool {
+	return search == nil || search.reached == len(search.via)
+}
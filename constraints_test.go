@@ -0,0 +1,76 @@
+package graphs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+func TestFindConstraints(t *testing.T) {
+	graph := MakeGraph()
+
+	tests := []struct {
+		name        string
+		constraints graphs.Constraints[int, string]
+		expected    []int // Third node of each expected path
+	}{
+		{"none", graphs.Constraints[int, string]{}, []int{3, 4}},
+		{"via", graphs.Constraints[int, string]{Via: []int{4}}, []int{4}},
+		{"via in order", graphs.Constraints[int, string]{Via: []int{2, 5}}, []int{3, 4}},
+		{"via out of order", graphs.Constraints[int, string]{Via: []int{5, 2}}, []int{}},
+		{"via both branches", graphs.Constraints[int, string]{Via: []int{3, 4}}, []int{}},
+		{"avoid node", graphs.Constraints[int, string]{AvoidNodes: []int{3}}, []int{4}},
+		{"avoid port", graphs.Constraints[int, string]{AvoidPorts: []graphs.PortRef[int, string]{{Node: 5, Port: "c"}}}, []int{3}},
+	}
+
+	for _, test := range tests {
+		paths, _, err := graph.FindRefContext(context.Background(), 1, "b", 6, "b", graphs.FindOptions[int, string]{Constraints: test.constraints})
+		if err != nil {
+			t.Fatalf("%s: error when finding paths: %s", test.name, err)
+		}
+
+		if len(paths) != len(test.expected) {
+			t.Fatalf("%s: expected %d paths, but got %d: %v", test.name, len(test.expected), len(paths), paths)
+		}
+
+		for i, path := range paths {
+			if path[2].Middle.Id() != test.expected[i] {
+				t.Fatalf("%s: expected path %d to pass %d, but got %v", test.name, i, test.expected[i], path)
+			}
+		}
+	}
+}
+
+func TestFindConstraintsWithLimits(t *testing.T) {
+	graph := MakeGraph()
+
+	options := graphs.FindOptions[int, string]{MaxPaths: 1, Constraints: graphs.Constraints[int, string]{AvoidNodes: []int{3}}}
+	paths, status, err := graph.FindRefContext(context.Background(), 1, "b", 6, "b", options)
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if status != graphs.FindMaxPaths || len(paths) != 1 || paths[0][2].Middle.Id() != 4 {
+		t.Fatalf("expected a single path via 4 and status max paths, but got %v and status %s", paths, status)
+	}
+}
+
+func TestFindMultiConstraints(t *testing.T) {
+	graph := MakeGraph()
+
+	sources := []graphs.PortRef[int, string]{{Node: 1, Port: "b"}}
+	targets := []graphs.PortRef[int, string]{{Node: 5, Port: "a"}, {Node: 6, Port: "b"}}
+
+	options := graphs.FindOptions[int, string]{Constraints: graphs.Constraints[int, string]{Via: []int{3}}}
+	groups, _, err := graph.FindMultiContext(context.Background(), sources, targets, options)
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	for _, group := range groups {
+		if len(group.Paths) != 1 || group.Paths[0][2].Middle.Id() != 3 {
+			t.Fatalf("expected a single path via 3 to %v, but got %v", group.To, group.Paths)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package graphs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// DOTOptions configure the output of WriteDOT.
+type DOTOptions[O, P comparable] struct {
+	Name      string                 // Name of the digraph, "graph" if empty
+	Highlight [][]*PathSegment[O, P] // Paths drawn in colour
+	Colors    []string               // Colours of the highlighted paths, cycled through
+}
+
+var defaultDOTColors = []string{"red", "blue", "darkgreen", "orange", "purple", "brown"}
+
+// WriteDOT writes the graph in the DOT language of Graphviz. Every node is a
+// record with its ID, one field per port and its inner connections. Outer
+// connections link the port fields, where bidirectional pairs are drawn as a
+// single undirected edge. Options may be nil.
+func (graph *Graph[O, P]) WriteDOT(w io.Writer, options *DOTOptions[O, P]) error {
+	if options == nil {
+		options = &DOTOptions[O, P]{}
+	}
+
+	name := options.Name
+	if name == "" {
+		name = "graph"
+	}
+
+	colors := options.Colors
+	if len(colors) == 0 {
+		colors = defaultDOTColors
+	}
+
+	nodeColors := map[O][]string{}
+	edgeColors := map[*Connection[O, P]][]string{}
+
+	for i, path := range options.Highlight {
+		color := colors[i%len(colors)]
+
+		connections, err := graph.PathConnections(path)
+		if err != nil {
+			return err
+		}
+
+		for _, connection := range connections {
+			edgeColors[connection] = appendColor(edgeColors[connection], color)
+		}
+
+		for _, segment := range path {
+			nodeColors[segment.Middle.id] = appendColor(nodeColors[segment.Middle.id], color)
+		}
+	}
+
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "digraph %s {\n", dotQuote(name))
+	fmt.Fprintln(out, "\tnode [shape=record];")
+	fmt.Fprintln(out)
+
+	ports := map[O][]P{}
+	for id, node := range graph.Nodes() {
+		ports[id] = graph.allPorts(node)
+
+		fields := make([]string, len(ports[id]))
+		for i, port := range ports[id] {
+			fields[i] = fmt.Sprintf("<p%d> %s", i, recordEscape(fmt.Sprint(port)))
+		}
+
+		label := fmt.Sprintf("{%s|{%s}", recordEscape(fmt.Sprint(id)), strings.Join(fields, "|"))
+		if transitions := innerLabels(node); len(transitions) > 0 {
+			label += "|" + strings.Join(transitions, `\n`)
+		}
+		label += "}"
+
+		fmt.Fprintf(out, "\t%s [label=\"%s\"", dotQuote(fmt.Sprint(id)), label)
+		if c, ok := nodeColors[id]; ok {
+			fmt.Fprintf(out, ", color=%s, penwidth=2", dotQuote(c[0]))
+		}
+		fmt.Fprintln(out, "];")
+	}
+
+	if len(graph.connections) > 0 {
+		fmt.Fprintln(out)
+	}
+
+	for _, edge := range graph.edges() {
+		from := fmt.Sprintf("%s:p%d", dotQuote(fmt.Sprint(edge.FromNode.id)), slices.Index(ports[edge.FromNode.id], edge.FromPort))
+		to := fmt.Sprintf("%s:p%d", dotQuote(fmt.Sprint(edge.ToNode.id)), slices.Index(ports[edge.ToNode.id], edge.ToPort))
+
+		attributes := []string{}
+		if edge.bidirectional {
+			attributes = append(attributes, "dir=none")
+		}
+
+		c := edgeColors[edge.Connection]
+		if edge.reverse != nil {
+			for _, color := range edgeColors[edge.reverse] {
+				c = appendColor(c, color)
+			}
+		}
+
+		if len(c) > 0 {
+			attributes = append(attributes, fmt.Sprintf("color=%s", dotQuote(strings.Join(c, ":"))), "penwidth=2")
+		}
+
+		if len(attributes) > 0 {
+			fmt.Fprintf(out, "\t%s -> %s [%s];\n", from, to, strings.Join(attributes, ", "))
+		} else {
+			fmt.Fprintf(out, "\t%s -> %s;\n", from, to)
+		}
+	}
+
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+// edge is an outer connection that is drawn once for both directions if it is
+// bidirectional.
+type edge[O, P comparable] struct {
+	*Connection[O, P]
+	reverse       *Connection[O, P]
+	bidirectional bool
+}
+
+// edges lists all outer connections in the order they have been connected,
+// skipping the reverse connection of bidirectional pairs.
+func (graph *Graph[O, P]) edges() []*edge[O, P] {
+	edges := []*edge[O, P]{}
+	seen := map[*Connection[O, P]]bool{}
+
+	for _, connection := range graph.connections {
+		if seen[connection] {
+			continue
+		}
+
+		e := &edge[O, P]{connection, nil, false}
+		for _, reverse := range graph.outgoing[portKey[O, P]{connection.ToNode.id, connection.ToPort}] {
+			if !seen[reverse] && reverse != connection && reverse.ToNode.Equals(connection.FromNode) && reverse.ToPort == connection.FromPort {
+				e.reverse = reverse
+				e.bidirectional = true
+				seen[reverse] = true
+				break
+			}
+		}
+
+		seen[connection] = true
+		edges = append(edges, e)
+	}
+
+	return edges
+}
+
+// allPorts lists the ports of a node used by inner connections followed by the
+// ones only used by outer connections leaving and then entering the node.
+func (graph *Graph[O, P]) allPorts(node *Node[O, P]) []P {
+	ports := slices.Collect(node.Ports())
+
+	seen := map[P]bool{}
+	for _, port := range ports {
+		seen[port] = true
+	}
+
+	add := func(port P) {
+		if !seen[port] {
+			seen[port] = true
+			ports = append(ports, port)
+		}
+	}
+
+	for _, connection := range graph.from[node.id] {
+		add(connection.FromPort)
+	}
+
+	for _, connection := range graph.to[node.id] {
+		add(connection.ToPort)
+	}
+
+	return ports
+}
+
+// innerLabels describes the inner connections of a node, one line each, with
+// bidirectional pairs merged into a single line.
+func innerLabels[O, P comparable](node *Node[O, P]) []string {
+	labels := []string{}
+	seen := map[portPair[P]]bool{}
+
+	for from, to := range node.InnerConnections() {
+		if seen[portPair[P]{from, to}] {
+			continue
+		}
+
+		arrow := "-\\>"
+		if _, ok := node.Transition(to, from); ok && from != to {
+			arrow = "\\<-\\>"
+			seen[portPair[P]{to, from}] = true
+		}
+
+		label := fmt.Sprintf("%s %s %s", recordEscape(fmt.Sprint(from)), arrow, recordEscape(fmt.Sprint(to)))
+		if transition, _ := node.Transition(from, to); transition.State != "" {
+			label += fmt.Sprintf(" (%s)", recordEscape(transition.State))
+		}
+
+		labels = append(labels, label)
+	}
+
+	return labels
+}
+
+func appendColor(colors []string, color string) []string {
+	if slices.Contains(colors, color) {
+		return colors
+	}
+
+	return append(colors, color)
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func recordEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, `{`, `\{`, `}`, `\}`, `|`, `\|`, `<`, `\<`, `>`, `\>`).Replace(s)
+}
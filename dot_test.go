@@ -0,0 +1,86 @@
+package graphs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+func TestWriteDOT(t *testing.T) {
+	graph := MakeGraph()
+	graph.ConnectRef(6, "b", 1, "a")
+
+	var builder strings.Builder
+	if err := graph.WriteDOT(&builder, &graphs.DOTOptions[int, string]{Name: "test"}); err != nil {
+		t.Fatalf("error when writing DOT: %s", err)
+	}
+
+	expected := `digraph "test" {
+	node [shape=record];
+
+	"1" [label="{1|{<p0> a|<p1> b}|a \<-\> b}"];
+	"2" [label="{2|{<p0> c|<p1> d|<p2> e}|c \<-\> d\nc \<-\> e}"];
+	"3" [label="{3|{<p0> a|<p1> b}|a \<-\> b}"];
+	"4" [label="{4|{<p0> a|<p1> b}|a \<-\> b}"];
+	"5" [label="{5|{<p0> a|<p1> b|<p2> c}|a \<-\> b\na \<-\> c}"];
+	"6" [label="{6|{<p0> a|<p1> b}|a \<-\> b}"];
+
+	"1":p1 -> "2":p0 [dir=none];
+	"2":p1 -> "3":p0 [dir=none];
+	"2":p2 -> "4":p0 [dir=none];
+	"3":p1 -> "5":p1 [dir=none];
+	"4":p1 -> "5":p2 [dir=none];
+	"5":p0 -> "6":p0 [dir=none];
+	"6":p1 -> "1":p0;
+}
+`
+
+	if builder.String() != expected {
+		t.Fatalf("unexpected DOT output:\n%s", builder.String())
+	}
+}
+
+func TestWriteDOTOuterPorts(t *testing.T) {
+	graph := MakeGraph()
+	graph.ConnectRef(6, "x", 1, "y")
+
+	var builder strings.Builder
+	if err := graph.WriteDOT(&builder, &graphs.DOTOptions[int, string]{}); err != nil {
+		t.Fatalf("error when writing DOT: %s", err)
+	}
+
+	for _, line := range []string{
+		`"1" [label="{1|{<p0> a|<p1> b|<p2> y}|a \<-\> b}"];`,
+		`"6" [label="{6|{<p0> a|<p1> b|<p2> x}|a \<-\> b}"];`,
+		`"6":p2 -> "1":p2;`,
+	} {
+		if !strings.Contains(builder.String(), line) {
+			t.Fatalf("expected DOT output to contain %s, but got:\n%s", line, builder.String())
+		}
+	}
+}
+
+func TestWriteDOTHighlight(t *testing.T) {
+	graph := MakeGraph()
+
+	paths, err := graph.FindRef(1, "b", 4, "b")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	var builder strings.Builder
+	if err := graph.WriteDOT(&builder, &graphs.DOTOptions[int, string]{Highlight: paths, Colors: []string{"green"}}); err != nil {
+		t.Fatalf("error when writing DOT: %s", err)
+	}
+
+	for _, line := range []string{
+		`"2":p2 -> "4":p0 [dir=none, color="green", penwidth=2];`,
+		`"2":p1 -> "3":p0 [dir=none];`,
+		`"4" [label="{4|{<p0> a|<p1> b}|a \<-\> b}", color="green", penwidth=2];`,
+	} {
+		if !strings.Contains(builder.String(), line) {
+			t.Fatalf("expected DOT output to contain %s, but got:\n%s", line, builder.String())
+		}
+	}
+}
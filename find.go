@@ -0,0 +1,99 @@
+package graphs
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// FindOptions bounds and restricts a path search. Zero values mean no limit.
+type FindOptions[O, P comparable] struct {
+	MaxDepth   int // Maximum number of nodes in a path
+	MaxPaths   int // Maximum number of paths to find
+	MaxVisited int // Maximum number of nodes to expand during the search
+	Deadline   time.Time
+
+	IgnoreOccupancy bool // Search occupied nodes and ports as well
+	Constraints     Constraints[O, P]
+}
+
+// FindStatus tells why a path search ended.
+type FindStatus int
+
+const (
+	FindExhausted FindStatus = iota
+	FindStopped
+	FindMaxDepth
+	FindMaxPaths
+	FindMaxVisited
+	FindDeadlineExceeded
+	FindCanceled
+)
+
+func (status FindStatus) String() string {
+	switch status {
+	case FindExhausted:
+		return "exhausted"
+	case FindStopped:
+		return "stopped"
+	case FindMaxDepth:
+		return "max depth reached"
+	case FindMaxPaths:
+		return "max paths reached"
+	case FindMaxVisited:
+		return "max visited nodes reached"
+	case FindDeadlineExceeded:
+		return "deadline exceeded"
+	case FindCanceled:
+		return "canceled"
+	default:
+		return fmt.Sprintf("FindStatus(%d)", int(status))
+	}
+}
+
+// Complete reports whether all paths have been found.
+func (status FindStatus) Complete() bool {
+	return status == FindExhausted
+}
+
+// FindContext works like Find, but stops as soon as ctx is done or one of the
+// limits in options is reached. The returned status tells why the search
+// ended. If it ended because of ctx or the deadline, the context's error is
+// returned alongside the paths found so far.
+func (graph *Graph[O, P]) FindContext(ctx context.Context, fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P, options FindOptions[O, P]) ([][]*PathSegment[O, P], FindStatus, error) {
+	if !options.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, options.Deadline)
+		defer cancel()
+	}
+
+	paths := [][]*PathSegment[O, P]{}
+	data := newDFSData(ctx, options, func(path []*PathSegment[O, P]) bool {
+		paths = append(paths, path)
+		return true
+	})
+
+	var entry P
+	graph.dfsFind(data, NewConnection(fromNode, fromPort, toNode, toPort), entry, nil)
+
+	status := data.Result()
+	if status == FindDeadlineExceeded || status == FindCanceled {
+		return paths, status, ctx.Err()
+	}
+
+	return paths, status, nil
+}
+
+func (graph *Graph[O, P]) FindRefContext(ctx context.Context, fromRef O, fromPort P, toRef O, toPort P, options FindOptions[O, P]) ([][]*PathSegment[O, P], FindStatus, error) {
+	fromNode, ok := graph.nodes[fromRef]
+	if !ok {
+		return nil, FindExhausted, fmt.Errorf("graph: from ref %v not found and thus cannot find paths", fromRef)
+	}
+
+	toNode, ok := graph.nodes[toRef]
+	if !ok {
+		return nil, FindExhausted, fmt.Errorf("graph: to ref %v not found and thus cannot find paths", toRef)
+	}
+
+	return graph.FindContext(ctx, fromNode, fromPort, toNode, toPort, options)
+}
@@ -0,0 +1,70 @@
+package graphs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+func TestFindContextExhausted(t *testing.T) {
+	graph := MakeGraph()
+
+	paths, status, err := graph.FindRefContext(context.Background(), 1, "b", 6, "b", graphs.FindOptions[int, string]{})
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if status != graphs.FindExhausted || len(paths) != 2 {
+		t.Fatalf("expected 2 paths and status exhausted, but got %d paths and status %s", len(paths), status)
+	}
+}
+
+func TestFindContextLimits(t *testing.T) {
+	graph := MakeGraph()
+
+	tests := []struct {
+		options graphs.FindOptions[int, string]
+		paths   int
+		status  graphs.FindStatus
+	}{
+		{graphs.FindOptions[int, string]{MaxPaths: 1}, 1, graphs.FindMaxPaths},
+		{graphs.FindOptions[int, string]{MaxDepth: 4}, 0, graphs.FindMaxDepth},
+		{graphs.FindOptions[int, string]{MaxDepth: 5}, 2, graphs.FindExhausted},
+		{graphs.FindOptions[int, string]{MaxVisited: 3}, 0, graphs.FindMaxVisited},
+	}
+
+	for _, test := range tests {
+		paths, status, err := graph.FindRefContext(context.Background(), 1, "b", 6, "b", test.options)
+		if err != nil {
+			t.Fatalf("error when finding paths with %+v: %s", test.options, err)
+		}
+
+		if status != test.status || len(paths) != test.paths {
+			t.Fatalf("expected %d paths and status %s with %+v, but got %d paths and status %s", test.paths, test.status, test.options, len(paths), status)
+		}
+	}
+}
+
+func TestFindContextCanceled(t *testing.T) {
+	graph := MakeGraph()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, status, err := graph.FindRefContext(ctx, 1, "b", 6, "b", graphs.FindOptions[int, string]{})
+	if !errors.Is(err, context.Canceled) || status != graphs.FindCanceled {
+		t.Fatalf("expected search to be canceled, but got status %s and error %v", status, err)
+	}
+}
+
+func TestFindContextDeadline(t *testing.T) {
+	graph := MakeGraph()
+
+	_, status, err := graph.FindRefContext(context.Background(), 1, "b", 6, "b", graphs.FindOptions[int, string]{Deadline: time.Now().Add(-time.Second)})
+	if !errors.Is(err, context.DeadlineExceeded) || status != graphs.FindDeadlineExceeded {
+		t.Fatalf("expected deadline to be exceeded, but got status %s and error %v", status, err)
+	}
+}
@@ -1,53 +1,338 @@
 package graphs
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"iter"
+	"maps"
+	"reflect"
 	"slices"
 
 	"github.com/moznion/go-optional"
 )
 
-type PathSegment[O, P comparable] Triple[optional.Option[P], *Node[O, P], optional.Option[P]]
+// PathSegment is a node passed by a path, entered via the port Left and left
+// via the port Right.
+type PathSegment[O, P comparable] struct {
+	Left       optional.Option[P]
+	Middle     *Node[O, P]
+	Right      optional.Option[P]
+	Connection *Connection[O, P] // Outer connection the node is entered by, nil for the first node
+}
 
 func (seg *PathSegment[O, P]) String() string {
 	return fmt.Sprintf("<%v, %v, %v>", seg.Left, seg.Middle, seg.Right)
 }
 
+// Attributes returns the attributes of the outer connection the segment's node
+// is entered by.
+func (seg *PathSegment[O, P]) Attributes() Attributes {
+	if seg.Connection == nil {
+		return nil
+	}
+
+	return seg.Connection.Attributes
+}
+
+// Transition returns the inner connection the segment passes its node with, if
+// it enters and leaves the node via connected ports.
+func (seg *PathSegment[O, P]) Transition() (Transition, bool) {
+	if seg.Left.IsNone() || seg.Right.IsNone() {
+		return Transition{}, false
+	}
+
+	return seg.Middle.Transition(seg.Left.Unwrap(), seg.Right.Unwrap())
+}
+
+type RequiredState[O comparable] struct {
+	Node  O
+	State string
+}
+
+// RequiredStates lists the state each node of a path has to be in, in the order
+// the nodes are passed. Nodes passed without a required state are left out.
+func RequiredStates[O, P comparable](path []*PathSegment[O, P]) []RequiredState[O] {
+	states := []RequiredState[O]{}
+	for _, segment := range path {
+		if transition, ok := segment.Transition(); ok && transition.State != "" {
+			states = append(states, RequiredState[O]{segment.Middle.id, transition.State})
+		}
+	}
+
+	return states
+}
+
 type Graph[O, P comparable] struct {
 	nodes       map[O]*Node[O, P]
+	order       []O
 	connections []*Connection[O, P]
+	outgoing    map[portKey[O, P]][]*Connection[O, P]
+	from        map[O][]*Connection[O, P] // Connections by the node they leave
+	to          map[O][]*Connection[O, P] // Connections by the node they enter
+	occupancy   *Occupancy[O, P]
 }
 
 func NewGraph[O, P comparable]() *Graph[O, P] {
-	return &Graph[O, P]{map[O]*Node[O, P]{}, []*Connection[O, P]{}}
+	return &Graph[O, P]{map[O]*Node[O, P]{}, []O{}, []*Connection[O, P]{}, map[portKey[O, P]][]*Connection[O, P]{}, map[O][]*Connection[O, P]{}, map[O][]*Connection[O, P]{}, NewOccupancy[O, P]()}
+}
+
+// Occupancy returns the nodes and ports currently unavailable to path searches.
+func (graph *Graph[O, P]) Occupancy() *Occupancy[O, P] {
+	return graph.occupancy
 }
 
+// Clone returns a deep copy of the graph including its nodes, the attributes of
+// each connection and the current occupancy.
+func (graph *Graph[O, P]) Clone() *Graph[O, P] {
+	clone := NewGraph[O, P]()
+	clone.occupancy = graph.occupancy.clone()
+	nodes := map[*Node[O, P]]*Node[O, P]{}
+
+	cloneNode := func(node *Node[O, P]) *Node[O, P] {
+		cloned, ok := nodes[node]
+		if !ok {
+			cloned = node.clone()
+			nodes[node] = cloned
+		}
+
+		return cloned
+	}
+
+	for _, id := range graph.order {
+		clone.AddNode(cloneNode(graph.nodes[id]))
+	}
+
+	// Attributes shared by several connections, like the ones created by
+	// ConnectBiWith, are cloned once and shared in the clone, too.
+	attributes := map[uintptr]Attributes{}
+	cloneAttributes := func(original Attributes) Attributes {
+		if original == nil {
+			return nil
+		}
+
+		key := reflect.ValueOf(original).Pointer()
+		cloned, ok := attributes[key]
+		if !ok {
+			cloned = maps.Clone(original)
+			attributes[key] = cloned
+		}
+
+		return cloned
+	}
+
+	for _, connection := range graph.connections {
+		clone.AddConnection(NewConnectionWith(cloneNode(connection.FromNode), connection.FromPort, cloneNode(connection.ToNode), connection.ToPort, cloneAttributes(connection.Attributes)))
+	}
+
+	return clone
+}
+
+// AddNode adds a node. A node with the same ID is replaced, see ReplaceNode.
 func (graph *Graph[O, P]) AddNode(node *Node[O, P]) {
+	if _, ok := graph.nodes[node.id]; ok {
+		graph.ReplaceNode(node) // Cannot fail as the node exists
+		return
+	}
+
+	graph.order = append(graph.order, node.id)
 	graph.nodes[node.id] = node
 }
 
+// AddNodeStrict adds a node like AddNode, but fails if a node with the same ID
+// already exists instead of overwriting it.
+func (graph *Graph[O, P]) AddNodeStrict(node *Node[O, P]) error {
+	if _, ok := graph.nodes[node.id]; ok {
+		return fmt.Errorf("graph: node %v already exists and cannot be re-added", node.id)
+	}
+
+	graph.AddNode(node)
+	return nil
+}
+
+// ReplaceNode replaces the node with the same ID and re-points all connections
+// from and to the old node to the new one.
+func (graph *Graph[O, P]) ReplaceNode(node *Node[O, P]) error {
+	if _, ok := graph.nodes[node.id]; !ok {
+		return fmt.Errorf("graph: node %v not found and thus cannot be replaced", node.id)
+	}
+
+	graph.nodes[node.id] = node
+
+	for _, connection := range graph.from[node.id] {
+		connection.FromNode = node
+	}
+
+	for _, connection := range graph.to[node.id] {
+		connection.ToNode = node
+	}
+
+	return nil
+}
+
+// RemoveNode removes a node and all connections from and to it.
+func (graph *Graph[O, P]) RemoveNode(id O) error {
+	if _, ok := graph.nodes[id]; !ok {
+		return fmt.Errorf("graph: node %v not found and thus cannot be removed", id)
+	}
+
+	delete(graph.nodes, id)
+	graph.order = slices.DeleteFunc(graph.order, func(o O) bool {
+		return o == id
+	})
+
+	graph.removeConnections(slices.Concat(graph.from[id], graph.to[id]))
+
+	return nil
+}
+
 func (graph *Graph[O, P]) AddConnection(connection *Connection[O, P]) error {
-	if slices.Contains(graph.connections, connection) {
+	key := portKey[O, P]{connection.FromNode.id, connection.FromPort}
+	if slices.Contains(graph.outgoing[key], connection) {
 		return fmt.Errorf("graph: connection %s already exists and cannot be re-added", connection)
 	}
 
 	graph.connections = append(graph.connections, connection)
+	graph.outgoing[key] = append(graph.outgoing[key], connection)
+	graph.from[connection.FromNode.id] = append(graph.from[connection.FromNode.id], connection)
+	graph.to[connection.ToNode.id] = append(graph.to[connection.ToNode.id], connection)
 	return nil
 }
 
+func (graph *Graph[O, P]) Node(id O) optional.Option[*Node[O, P]] {
+	node, ok := graph.nodes[id]
+	if !ok {
+		return optional.None[*Node[O, P]]()
+	}
+
+	return optional.Some(node)
+}
+
+// Nodes iterates over all nodes in the order they have been added.
+func (graph *Graph[O, P]) Nodes() iter.Seq2[O, *Node[O, P]] {
+	return func(yield func(O, *Node[O, P]) bool) {
+		for _, id := range graph.order {
+			if !yield(id, graph.nodes[id]) {
+				return
+			}
+		}
+	}
+}
+
+// Connections iterates over all connections in the order they have been added.
+func (graph *Graph[O, P]) Connections() iter.Seq[*Connection[O, P]] {
+	return func(yield func(*Connection[O, P]) bool) {
+		for _, connection := range graph.connections {
+			if !yield(connection) {
+				return
+			}
+		}
+	}
+}
+
+// ConnectionsFrom iterates over all connections leaving a node in the order
+// they have been added.
+func (graph *Graph[O, P]) ConnectionsFrom(node *Node[O, P]) iter.Seq[*Connection[O, P]] {
+	return func(yield func(*Connection[O, P]) bool) {
+		for _, connection := range graph.from[node.id] {
+			if !yield(connection) {
+				return
+			}
+		}
+	}
+}
+
+// ConnectionsTo iterates over all connections entering a node in the order
+// they have been added.
+func (graph *Graph[O, P]) ConnectionsTo(node *Node[O, P]) iter.Seq[*Connection[O, P]] {
+	return func(yield func(*Connection[O, P]) bool) {
+		for _, connection := range graph.to[node.id] {
+			if !yield(connection) {
+				return
+			}
+		}
+	}
+}
+
+// RemoveConnection removes the connection that equals the given one.
+func (graph *Graph[O, P]) RemoveConnection(connection *Connection[O, P]) error {
+	key := portKey[O, P]{connection.FromNode.id, connection.FromPort}
+
+	idx := slices.IndexFunc(graph.outgoing[key], connection.Equals)
+	if idx < 0 {
+		return fmt.Errorf("graph: connection %s not found and thus cannot be removed", connection)
+	}
+
+	graph.removeConnections([]*Connection[O, P]{graph.outgoing[key][idx]})
+	return nil
+}
+
+func (graph *Graph[O, P]) removeConnections(removed []*Connection[O, P]) {
+	if len(removed) == 0 {
+		return
+	}
+
+	set := map[*Connection[O, P]]bool{}
+	for _, connection := range removed {
+		set[connection] = true
+	}
+
+	del := func(c *Connection[O, P]) bool {
+		return set[c]
+	}
+
+	for _, connection := range removed {
+		deleteFrom(graph.outgoing, portKey[O, P]{connection.FromNode.id, connection.FromPort}, del)
+		deleteFrom(graph.from, connection.FromNode.id, del)
+		deleteFrom(graph.to, connection.ToNode.id, del)
+	}
+
+	graph.connections = slices.DeleteFunc(graph.connections, del)
+}
+
+// deleteFrom removes connections from an index entry and drops the entry once
+// it is empty.
+func deleteFrom[K comparable, O, P comparable](index map[K][]*Connection[O, P], key K, del func(*Connection[O, P]) bool) {
+	connections, ok := index[key]
+	if !ok {
+		return
+	}
+
+	connections = slices.DeleteFunc(connections, del)
+	if len(connections) == 0 {
+		delete(index, key)
+	} else {
+		index[key] = connections
+	}
+}
+
 func (graph *Graph[O, P]) Connect(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P) error {
-	return graph.AddConnection(NewConnection(fromNode, fromPort, toNode, toPort))
+	return graph.ConnectWith(fromNode, fromPort, toNode, toPort, nil)
+}
+
+func (graph *Graph[O, P]) ConnectWith(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P, attributes Attributes) error {
+	return graph.AddConnection(NewConnectionWith(fromNode, fromPort, toNode, toPort, attributes))
 }
 
 func (graph *Graph[O, P]) ConnectBi(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P) error {
-	if err := graph.AddConnection(NewConnection(fromNode, fromPort, toNode, toPort)); err != nil {
+	return graph.ConnectBiWith(fromNode, fromPort, toNode, toPort, nil)
+}
+
+// ConnectBiWith connects two ports in both directions. Both connections share
+// the same attributes.
+func (graph *Graph[O, P]) ConnectBiWith(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P, attributes Attributes) error {
+	if err := graph.ConnectWith(fromNode, fromPort, toNode, toPort, attributes); err != nil {
 		return err
 	}
 
-	return graph.AddConnection(NewConnection(toNode, toPort, fromNode, fromPort))
+	return graph.ConnectWith(toNode, toPort, fromNode, fromPort, attributes)
 }
 
 func (graph *Graph[O, P]) ConnectRef(fromRef O, fromPort P, toRef O, toPort P) error {
+	return graph.ConnectRefWith(fromRef, fromPort, toRef, toPort, nil)
+}
+
+func (graph *Graph[O, P]) ConnectRefWith(fromRef O, fromPort P, toRef O, toPort P, attributes Attributes) error {
 	fromNode, ok := graph.nodes[fromRef]
 	if !ok {
 		return fmt.Errorf("graph: from ref %v not found and thus cannot be used for connection", fromRef)
@@ -58,34 +343,134 @@ func (graph *Graph[O, P]) ConnectRef(fromRef O, fromPort P, toRef O, toPort P) e
 		return fmt.Errorf("graph: to ref %v not found and thus cannot be used for connection", toRef)
 	}
 
-	return graph.Connect(fromNode, fromPort, toNode, toPort)
+	return graph.ConnectWith(fromNode, fromPort, toNode, toPort, attributes)
 }
 
 func (graph *Graph[O, P]) ConnectRefBi(fromRef O, fromPort P, toRef O, toPort P) error {
-	if err := graph.ConnectRef(fromRef, fromPort, toRef, toPort); err != nil {
+	return graph.ConnectRefBiWith(fromRef, fromPort, toRef, toPort, nil)
+}
+
+func (graph *Graph[O, P]) ConnectRefBiWith(fromRef O, fromPort P, toRef O, toPort P, attributes Attributes) error {
+	if err := graph.ConnectRefWith(fromRef, fromPort, toRef, toPort, attributes); err != nil {
+		return err
+	}
+
+	return graph.ConnectRefWith(toRef, toPort, fromRef, fromPort, attributes)
+}
+
+func (graph *Graph[O, P]) Disconnect(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P) error {
+	return graph.RemoveConnection(NewConnection(fromNode, fromPort, toNode, toPort))
+}
+
+// DisconnectBi removes the connections between two ports in both directions.
+// If one of them does not exist, none is removed.
+func (graph *Graph[O, P]) DisconnectBi(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P) error {
+	forward := NewConnection(fromNode, fromPort, toNode, toPort)
+	backward := NewConnection(toNode, toPort, fromNode, fromPort)
+
+	for _, connection := range []*Connection[O, P]{forward, backward} {
+		if !slices.ContainsFunc(graph.outgoing[portKey[O, P]{connection.FromNode.id, connection.FromPort}], connection.Equals) {
+			return fmt.Errorf("graph: connection %s not found and thus cannot be removed", connection)
+		}
+	}
+
+	if err := graph.RemoveConnection(forward); err != nil {
 		return err
 	}
 
-	return graph.ConnectRef(toRef, toPort, fromRef, fromPort)
+	return graph.RemoveConnection(backward)
+}
+
+func (graph *Graph[O, P]) DisconnectRef(fromRef O, fromPort P, toRef O, toPort P) error {
+	fromNode, ok := graph.nodes[fromRef]
+	if !ok {
+		return fmt.Errorf("graph: from ref %v not found and thus cannot be used for disconnection", fromRef)
+	}
+
+	toNode, ok := graph.nodes[toRef]
+	if !ok {
+		return fmt.Errorf("graph: to ref %v not found and thus cannot be used for disconnection", toRef)
+	}
+
+	return graph.Disconnect(fromNode, fromPort, toNode, toPort)
+}
+
+func (graph *Graph[O, P]) DisconnectRefBi(fromRef O, fromPort P, toRef O, toPort P) error {
+	fromNode, ok := graph.nodes[fromRef]
+	if !ok {
+		return fmt.Errorf("graph: from ref %v not found and thus cannot be used for disconnection", fromRef)
+	}
+
+	toNode, ok := graph.nodes[toRef]
+	if !ok {
+		return fmt.Errorf("graph: to ref %v not found and thus cannot be used for disconnection", toRef)
+	}
+
+	return graph.DisconnectBi(fromNode, fromPort, toNode, toPort)
 }
 
 func (graph *Graph[O, P]) FindConnection(node *Node[O, P], port P) (*Connection[O, P], bool) {
-	for _, connection := range graph.connections {
-		if connection.FromNode.Equals(node) && connection.FromPort == port {
-			return connection, true
+	connections := graph.outgoing[portKey[O, P]{node.id, port}]
+	if len(connections) == 0 {
+		return nil, false
+	}
+
+	return connections[0], true
+}
+
+func (graph *Graph[O, P]) FindConnections(node *Node[O, P], port P) []*Connection[O, P] {
+	return slices.Clone(graph.outgoing[portKey[O, P]{node.id, port}])
+}
+
+// PathConnections returns the outer connections taken between the segments of
+// a path found in this graph.
+func (graph *Graph[O, P]) PathConnections(path []*PathSegment[O, P]) ([]*Connection[O, P], error) {
+	connections := []*Connection[O, P]{}
+
+	for i := 1; i < len(path); i++ {
+		from, to := path[i-1], path[i]
+		if from.Right.IsNone() || to.Left.IsNone() {
+			return nil, fmt.Errorf("graph: segments %s and %s are not connected by ports", from, to)
+		}
+
+		idx := slices.IndexFunc(graph.outgoing[portKey[O, P]{from.Middle.id, from.Right.Unwrap()}], func(connection *Connection[O, P]) bool {
+			return connection.ToNode.Equals(to.Middle) && connection.ToPort == to.Left.Unwrap()
+		})
+
+		if idx < 0 {
+			return nil, fmt.Errorf("graph: no connection between segments %s and %s", from, to)
 		}
+
+		connections = append(connections, graph.outgoing[portKey[O, P]{from.Middle.id, from.Right.Unwrap()}][idx])
 	}
 
-	return nil, false
+	return connections, nil
 }
 
 func (graph *Graph[O, P]) Find(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P) [][]*PathSegment[O, P] {
-	data := &dFSData[O, P]{[]*Node[O, P]{}, []*PathSegment[O, P]{}, [][]*PathSegment[O, P]{}}
-	graph.dfsFind(data, NewConnection(fromNode, fromPort, toNode, toPort))
-	return data.Paths
+	return slices.Collect(graph.Paths(fromNode, fromPort, toNode, toPort))
 }
 
 func (graph *Graph[O, P]) FindRef(fromRef O, fromPort P, toRef O, toPort P) ([][]*PathSegment[O, P], error) {
+	paths, err := graph.PathsRef(fromRef, fromPort, toRef, toPort)
+	if err != nil {
+		return nil, err
+	}
+
+	return slices.Collect(paths), nil
+}
+
+// Paths yields all paths between two ports one at a time while the search is
+// running. The search stops as soon as the consumer stops iterating.
+func (graph *Graph[O, P]) Paths(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P) iter.Seq[[]*PathSegment[O, P]] {
+	return func(yield func([]*PathSegment[O, P]) bool) {
+		data := newDFSData(context.Background(), FindOptions[O, P]{}, yield)
+		var entry P
+		graph.dfsFind(data, NewConnection(fromNode, fromPort, toNode, toPort), entry, nil)
+	}
+}
+
+func (graph *Graph[O, P]) PathsRef(fromRef O, fromPort P, toRef O, toPort P) (iter.Seq[[]*PathSegment[O, P]], error) {
 	fromNode, ok := graph.nodes[fromRef]
 	if !ok {
 		return nil, fmt.Errorf("graph: from ref %v not found and thus cannot find paths", fromRef)
@@ -96,68 +481,204 @@ func (graph *Graph[O, P]) FindRef(fromRef O, fromPort P, toRef O, toPort P) ([][
 		return nil, fmt.Errorf("graph: to ref %v not found and thus cannot find paths", toRef)
 	}
 
-	return graph.Find(fromNode, fromPort, toNode, toPort), nil
+	return graph.Paths(fromNode, fromPort, toNode, toPort), nil
 }
 
-func (graph *Graph[O, P]) dfsFind(data *dFSData[O, P], current *Connection[O, P]) {
+// dfsFind continues the search at current.FromNode, which has been entered via
+// entry by the outer connection via and is left via current.FromPort. Every
+// connection leaving that port is explored.
+func (graph *Graph[O, P]) dfsFind(data *dFSData[O, P], current *Connection[O, P], entry P, via *Connection[O, P]) {
+	if data.Split != nil && data.Split(current, entry, via) {
+		return
+	}
+
 	if slices.Contains(data.Visited, current.FromNode) {
 		return
 	}
 
-	var entry P
-	if len(data.CurrentPath)-1 >= 0 {
-		previous, ok := graph.FindConnection(data.CurrentPath[len(data.CurrentPath)-1].Middle, data.CurrentPath[len(data.CurrentPath)-1].Right.Unwrap())
+	if !data.Options.IgnoreOccupancy && !graph.occupancy.passable(current.FromNode.id, entry, current.FromPort, len(data.CurrentPath) == 0) {
+		return
+	}
 
-		if ok {
-			entry = previous.ToPort
-		}
+	if !data.Constraints.allows(current.FromNode.id, entry, current.FromPort, len(data.CurrentPath) == 0) {
+		return
+	}
+
+	if !data.Enter() {
+		return
 	}
 
 	data.Visited = append(data.Visited, current.FromNode)
-	data.CurrentPath = append(data.CurrentPath, &PathSegment[O, P]{optional.Some(entry), current.FromNode, optional.Some(current.FromPort)})
+	data.CurrentPath = append(data.CurrentPath, &PathSegment[O, P]{optional.Some(entry), current.FromNode, optional.Some(current.FromPort), via})
 
-	if current.IsSelf() {
-		graph.dfsHandleFound(data)
-		return
+	if data.Constraints.enter(current.FromNode.id) {
+		defer data.Constraints.leave()
 	}
 
-	next, ok := graph.FindConnection(current.FromNode, current.FromPort)
-	if !ok {
-		data.CurrentPath = data.CurrentPath[:len(data.CurrentPath)-1]
+	// Targets reached from this node, so that each of them is found only once.
+	var reached []P
+
+	if data.reaches(current, current.FromPort) {
+		reached = append(reached, current.FromPort)
+		graph.dfsHandleFound(data, current.FromPort, false)
+
+		if data.Targets == nil || data.Stopped {
+			data.pop()
+			return
+		}
+	}
+
+	for _, next := range graph.outgoing[portKey[O, P]{current.FromNode.id, current.FromPort}] {
+		for _, port := range next.ToNode.Next(next.ToPort) {
+			// This happens, when the next port belongs to the same node as the
+			// current one.
+			if data.reaches(current, port) && !slices.Contains(reached, port) {
+				reached = append(reached, port)
+				graph.dfsHandleFound(data, port, true)
+
+				if data.Targets == nil || data.Stopped {
+					data.pop()
+					return
+				}
+			}
+
+			new := NewConnection(next.ToNode, port, current.ToNode, current.ToPort)
+			graph.dfsFind(data, new, next.ToPort, next)
+
+			if data.Stopped {
+				return
+			}
+		}
+	}
+
+	data.pop()
+}
+
+// dfsHandleFound hands a copy of the current path to the consumer, so that
+// later changes to the search state do not leak into paths already found. The
+// path ends by leaving its last node via port.
+func (graph *Graph[O, P]) dfsHandleFound(data *dFSData[O, P], port P, clearEnds bool) {
+	if !data.Constraints.complete() {
 		return
 	}
 
-	for _, port := range next.ToNode.Next(next.ToPort) {
-		// This happens, when the next port belongs to the same node as the
-		// current one.
-		if current.EqualNodes() && port == current.ToPort {
-			data.CurrentPath[0].Left = optional.None[P]()                        // Clear first port
-			data.CurrentPath[len(data.CurrentPath)-1].Right = optional.None[P]() // Clear last port
-			graph.dfsHandleFound(data)
-			return
+	currentPath := make([]*PathSegment[O, P], len(data.CurrentPath))
+	for i, segment := range data.CurrentPath {
+		copied := *segment
+		currentPath[i] = &copied
+	}
+
+	if clearEnds {
+		currentPath[0].Left = optional.None[P]()                   // Clear first port
+		currentPath[len(currentPath)-1].Right = optional.None[P]() // Clear last port
+	}
+
+	var yielded bool
+	if data.Targets != nil {
+		yielded = data.Reached(portKey[O, P]{currentPath[len(currentPath)-1].Middle.id, port}, currentPath)
+	} else {
+		yielded = data.Yield(currentPath)
+	}
+
+	if !yielded {
+		data.Stop(FindStopped)
+	}
+
+	data.Found++
+	if data.Options.MaxPaths > 0 && data.Found >= data.Options.MaxPaths {
+		data.Stop(FindMaxPaths)
+	}
+}
+
+type portKey[O, P comparable] struct {
+	node O
+	port P
+}
+
+type dFSData[O comparable, P comparable] struct {
+	Visited      []*Node[O, P]
+	CurrentPath  []*PathSegment[O, P]
+	Yield        func([]*PathSegment[O, P]) bool
+	Split        func(current *Connection[O, P], entry P, via *Connection[O, P]) bool
+	Context      context.Context
+	Options      FindOptions[O, P]
+	Constraints  *searchConstraints[O, P]
+	Expanded     int
+	Found        int
+	DepthLimited bool
+	Stopped      bool
+	Status       FindStatus
+
+	// Targets replaces the single target of a search, if set. Paths to any of
+	// them are handed to Reached instead of Yield, and the search goes on
+	// behind them, as more targets might follow.
+	Targets map[portKey[O, P]]bool
+	Reached func(target portKey[O, P], path []*PathSegment[O, P]) bool
+}
+
+func newDFSData[O, P comparable](ctx context.Context, options FindOptions[O, P], yield func([]*PathSegment[O, P]) bool) *dFSData[O, P] {
+	return &dFSData[O, P]{
+		Visited:     []*Node[O, P]{},
+		CurrentPath: []*PathSegment[O, P]{},
+		Yield:       yield,
+		Context:     ctx,
+		Options:     options,
+		Constraints: newSearchConstraints(options.Constraints),
+		Status:      FindExhausted,
+	}
+}
+
+// Enter reports whether the search may expand one more node and counts it.
+func (data *dFSData[O, P]) Enter() bool {
+	if err := data.Context.Err(); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			data.Stop(FindDeadlineExceeded)
+		} else {
+			data.Stop(FindCanceled)
 		}
+		return false
+	}
 
-		new := NewConnection(next.ToNode, port, current.ToNode, current.ToPort)
-		graph.dfsFind(data, new)
+	if data.Options.MaxVisited > 0 && data.Expanded >= data.Options.MaxVisited {
+		data.Stop(FindMaxVisited)
+		return false
 	}
 
-	data.CurrentPath = data.CurrentPath[:len(data.CurrentPath)-1]
+	if data.Options.MaxDepth > 0 && len(data.CurrentPath) >= data.Options.MaxDepth {
+		data.DepthLimited = true
+		return false
+	}
 
-	idx := slices.Index(data.Visited, current.FromNode)
-	data.Visited = slices.Delete(data.Visited, idx, idx+1)
+	data.Expanded++
+	return true
 }
 
-func (graph *Graph[O, P]) dfsHandleFound(data *dFSData[O, P]) {
-	var currentPath []*PathSegment[O, P]
-	currentPath = append(currentPath, data.CurrentPath...)
+// reaches reports whether leaving current's from node via port ends a path.
+func (data *dFSData[O, P]) reaches(current *Connection[O, P], port P) bool {
+	if data.Targets != nil {
+		return data.Targets[portKey[O, P]{current.FromNode.id, port}]
+	}
+
+	return current.EqualNodes() && port == current.ToPort
+}
 
-	data.Paths = append(data.Paths, currentPath)
+// pop removes the last node from the current path.
+func (data *dFSData[O, P]) pop() {
 	data.Visited = data.Visited[:len(data.Visited)-1]
 	data.CurrentPath = data.CurrentPath[:len(data.CurrentPath)-1]
 }
 
-type dFSData[O comparable, P comparable] struct {
-	Visited     []*Node[O, P]
-	CurrentPath []*PathSegment[O, P]
-	Paths       [][]*PathSegment[O, P]
+func (data *dFSData[O, P]) Stop(status FindStatus) {
+	if !data.Stopped {
+		data.Stopped = true
+		data.Status = status
+	}
+}
+
+func (data *dFSData[O, P]) Result() FindStatus {
+	if !data.Stopped && data.DepthLimited {
+		return FindMaxDepth
+	}
+
+	return data.Status
 }
@@ -1,6 +1,7 @@
 package graphs_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/yannickkirschen/graphs"
@@ -12,7 +13,7 @@ import (
    a b c d a b b a a b
 */
 
-func TestFind(t *testing.T) {
+func MakeGraph() *graphs.Graph[int, string] {
 	one := graphs.NewNode[int, string](1)
 	two := graphs.NewNode[int, string](2)
 	three := graphs.NewNode[int, string](3)
@@ -44,6 +45,12 @@ func TestFind(t *testing.T) {
 	graph.ConnectRefBi(4, "b", 5, "c")
 	graph.ConnectRefBi(5, "a", 6, "a")
 
+	return graph
+}
+
+func TestFind(t *testing.T) {
+	graph := MakeGraph()
+
 	paths, err := graph.FindRef(1, "b", 4, "b")
 	if err != nil {
 		t.Fatalf("error when finding paths: %s", err)
@@ -62,3 +69,381 @@ func TestFind(t *testing.T) {
 		t.Fatalf("expected path to be 1 -> 2 -> 4 but got %v", path)
 	}
 }
+
+func TestPaths(t *testing.T) {
+	graph := MakeGraph()
+
+	paths, err := graph.PathsRef(1, "b", 6, "b")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	count := 0
+	for path := range paths {
+		if path[len(path)-1].Middle.Id() != 6 {
+			t.Fatalf("expected path to end at 6, but got %v", path)
+		}
+
+		count++
+		break
+	}
+
+	if count != 1 {
+		t.Fatalf("expected to stop after 1 path, but got %d", count)
+	}
+
+	all, _ := graph.FindRef(1, "b", 6, "b")
+	if len(all) != 2 {
+		t.Fatalf("expected 2 paths in total, but got %d: %v", len(all), all)
+	}
+}
+
+/*
+            ,- A -.
+   in S out       in C out
+            `- B -´
+*/
+
+func MakeFanOutGraph() *graphs.Graph[string, string] {
+	graph := graphs.NewGraph[string, string]()
+
+	for _, id := range []string{"S", "A", "B", "C"} {
+		node := graphs.NewNode[string, string](id)
+		node.ConnectBi("in", "out")
+		graph.AddNode(node)
+	}
+
+	graph.ConnectRef("S", "out", "A", "in")
+	graph.ConnectRef("S", "out", "B", "in")
+	graph.ConnectRef("A", "out", "C", "in")
+	graph.ConnectRef("B", "out", "C", "in")
+
+	return graph
+}
+
+func TestFindConnections(t *testing.T) {
+	graph := MakeFanOutGraph()
+	splitter := graphs.NewNode[string, string]("S")
+
+	connections := graph.FindConnections(splitter, "out")
+	if len(connections) != 2 {
+		t.Fatalf("expected 2 connections, but got %d: %v", len(connections), connections)
+	}
+
+	if connections[0].ToNode.Id() != "A" || connections[1].ToNode.Id() != "B" {
+		t.Fatalf("expected connections to A and B, but got %v", connections)
+	}
+
+	connection, ok := graph.FindConnection(splitter, "out")
+	if !ok || connection.ToNode.Id() != "A" {
+		t.Fatalf("expected first connection to lead to A, but got %v", connection)
+	}
+
+	if connections := graph.FindConnections(splitter, "in"); len(connections) != 0 {
+		t.Fatalf("expected no connections, but got %v", connections)
+	}
+}
+
+func TestFindFanOut(t *testing.T) {
+	graph := MakeFanOutGraph()
+
+	paths, err := graph.FindRef("S", "out", "C", "out")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths, but got %d: %v", len(paths), paths)
+	}
+
+	if paths[0][1].Middle.Id() != "A" || paths[1][1].Middle.Id() != "B" {
+		t.Fatalf("expected paths via A and via B, but got %v", paths)
+	}
+
+	for _, path := range paths {
+		if path[2].Left.Unwrap() != "in" || path[2].Right.Unwrap() != "out" {
+			t.Fatalf("expected C to be passed from 'in' to 'out', but got %v", path[2])
+		}
+	}
+}
+
+func TestKShortestPathsFanOut(t *testing.T) {
+	graph := MakeFanOutGraph()
+
+	paths, _, err := graph.KShortestPathsRef("S", "out", "C", "out", 3, nil)
+	if err != nil {
+		t.Fatalf("error when finding k shortest paths: %s", err)
+	}
+
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths, but got %d: %v", len(paths), paths)
+	}
+}
+
+func TestAddNodeStrict(t *testing.T) {
+	graph := MakeGraph()
+
+	if err := graph.AddNodeStrict(graphs.NewNode[int, string](1)); err == nil {
+		t.Fatalf("expected error when adding duplicate node")
+	}
+
+	if err := graph.AddNodeStrict(graphs.NewNode[int, string](7)); err != nil {
+		t.Fatalf("error when adding new node: %s", err)
+	}
+}
+
+func TestRemoveNode(t *testing.T) {
+	graph := MakeGraph()
+
+	if err := graph.RemoveNode(3); err != nil {
+		t.Fatalf("error when removing node: %s", err)
+	}
+
+	if err := graph.RemoveNode(3); err == nil {
+		t.Fatalf("expected error when removing node twice")
+	}
+
+	paths, err := graph.FindRef(1, "b", 6, "b")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 1 || paths[0][2].Middle.Id() != 4 {
+		t.Fatalf("expected 1 path via 4, but got %v", paths)
+	}
+
+	if connections := graph.FindConnections(graphs.NewNode[int, string](2), "d"); len(connections) != 0 {
+		t.Fatalf("expected connections to 3 to be removed, but got %v", connections)
+	}
+
+	if to := slices.Collect(graph.ConnectionsTo(graph.Node(5).Unwrap())); len(to) != 2 {
+		t.Fatalf("expected 2 connections to 5 after removing 3, but got %v", to)
+	}
+}
+
+func TestDisconnect(t *testing.T) {
+	graph := MakeGraph()
+
+	if err := graph.DisconnectRefBi(2, "e", 4, "a"); err != nil {
+		t.Fatalf("error when disconnecting: %s", err)
+	}
+
+	if err := graph.DisconnectRef(2, "e", 4, "a"); err == nil {
+		t.Fatalf("expected error when disconnecting twice")
+	}
+
+	paths, _ := graph.FindRef(1, "b", 6, "b")
+	if len(paths) != 1 || paths[0][2].Middle.Id() != 3 {
+		t.Fatalf("expected 1 path via 3, but got %v", paths)
+	}
+}
+
+func TestDisconnectBiMissingReverse(t *testing.T) {
+	graph := MakeGraph()
+	graph.ConnectRef(6, "b", 1, "a")
+
+	if err := graph.DisconnectRefBi(6, "b", 1, "a"); err == nil {
+		t.Fatalf("expected error when the reverse connection is missing")
+	}
+
+	if _, ok := graph.FindConnection(graph.Node(6).Unwrap(), "b"); !ok {
+		t.Fatalf("expected existing direction to be kept")
+	}
+}
+
+func TestAddNodeReplaces(t *testing.T) {
+	graph := MakeGraph()
+
+	three := graphs.NewNode[int, string](3)
+	graph.AddNode(three)
+
+	for connection := range graph.Connections() {
+		if connection.FromNode.Id() == 3 && connection.FromNode != three || connection.ToNode.Id() == 3 && connection.ToNode != three {
+			t.Fatalf("expected connection %s to point to the new node 3", connection)
+		}
+	}
+
+	paths, _ := graph.FindRef(1, "b", 6, "b")
+	if len(paths) != 1 || paths[0][2].Middle.Id() != 4 {
+		t.Fatalf("expected 1 path via 4 as new node 3 has no inner connections, but got %v", paths)
+	}
+}
+
+func TestReplaceNode(t *testing.T) {
+	graph := MakeGraph()
+
+	three := graphs.NewNode[int, string](3)
+	if err := graph.ReplaceNode(three); err != nil {
+		t.Fatalf("error when replacing node: %s", err)
+	}
+
+	paths, _ := graph.FindRef(1, "b", 6, "b")
+	if len(paths) != 1 || paths[0][2].Middle.Id() != 4 {
+		t.Fatalf("expected 1 path via 4 as new node 3 has no inner connections, but got %v", paths)
+	}
+
+	three.ConnectBi("a", "b")
+
+	paths, _ = graph.FindRef(1, "b", 6, "b")
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths after connecting new node 3, but got %v", paths)
+	}
+
+	if err := graph.ReplaceNode(graphs.NewNode[int, string](7)); err == nil {
+		t.Fatalf("expected error when replacing unknown node")
+	}
+}
+
+func TestNodes(t *testing.T) {
+	graph := MakeGraph()
+
+	ids := []int{}
+	for id, node := range graph.Nodes() {
+		if node.Id() != id {
+			t.Fatalf("expected node with ID %d, but got %v", id, node)
+		}
+		ids = append(ids, id)
+	}
+
+	if !slices.Equal(ids, []int{1, 2, 3, 4, 5, 6}) {
+		t.Fatalf("expected nodes [1 2 3 4 5 6], but got %v", ids)
+	}
+
+	if graph.Node(3).IsNone() || graph.Node(7).IsSome() {
+		t.Fatalf("expected node 3 to exist and node 7 not to exist")
+	}
+}
+
+func TestConnections(t *testing.T) {
+	graph := MakeGraph()
+
+	if connections := slices.Collect(graph.Connections()); len(connections) != 12 {
+		t.Fatalf("expected 12 connections, but got %d: %v", len(connections), connections)
+	}
+
+	two := graph.Node(2).Unwrap()
+
+	from := slices.Collect(graph.ConnectionsFrom(two))
+	if len(from) != 3 {
+		t.Fatalf("expected 3 connections from 2, but got %d: %v", len(from), from)
+	}
+
+	to := slices.Collect(graph.ConnectionsTo(two))
+	if len(to) != 3 {
+		t.Fatalf("expected 3 connections to 2, but got %d: %v", len(to), to)
+	}
+
+	for _, connection := range to {
+		if connection.ToNode.Id() != 2 {
+			t.Fatalf("expected connection to 2, but got %v", connection)
+		}
+	}
+}
+
+func TestPathConnections(t *testing.T) {
+	graph := MakeGraph()
+	graph.DisconnectRefBi(2, "e", 4, "a")
+	graph.ConnectRefBiWith(2, "e", 4, "a", graphs.Attributes{"length": 120, "cable": "A2"})
+
+	paths, _ := graph.FindRef(1, "b", 4, "b")
+	if len(paths) != 1 {
+		t.Fatalf("expected 1 path, but got %v", paths)
+	}
+
+	connections, err := graph.PathConnections(paths[0])
+	if err != nil {
+		t.Fatalf("error when resolving connections: %s", err)
+	}
+
+	if len(connections) != 2 {
+		t.Fatalf("expected 2 connections, but got %v", connections)
+	}
+
+	if connections[0].Attributes != nil {
+		t.Fatalf("expected first connection to have no attributes, but got %v", connections[0].Attributes)
+	}
+
+	length, ok := graphs.Attribute[int](connections[1].Attributes, "length")
+	if !ok || length != 120 {
+		t.Fatalf("expected length 120, but got %v", connections[1].Attributes)
+	}
+
+	if _, ok := graphs.Attribute[string](connections[1].Attributes, "length"); ok {
+		t.Fatalf("expected length not to be a string")
+	}
+
+	backward, _ := graph.FindRef(4, "a", 1, "a")
+	connections, _ = graph.PathConnections(backward[0])
+	if cable, _ := graphs.Attribute[string](connections[0].Attributes, "cable"); cable != "A2" {
+		t.Fatalf("expected backward connection to share attributes, but got %v", connections[0].Attributes)
+	}
+
+	if paths[0][0].Attributes() != nil || paths[0][1].Attributes() != nil {
+		t.Fatalf("expected no attributes before entering 4, but got %v", paths[0])
+	}
+
+	if length, _ := graphs.Attribute[int](paths[0][2].Attributes(), "length"); length != 120 {
+		t.Fatalf("expected segment of 4 to expose length 120, but got %v", paths[0][2].Attributes())
+	}
+}
+
+func TestCloneAttributes(t *testing.T) {
+	graph := MakeGraph()
+	graph.DisconnectRefBi(2, "e", 4, "a")
+	graph.ConnectRefBiWith(2, "e", 4, "a", graphs.Attributes{"cable": "A2"})
+
+	clone := graph.Clone()
+	forward, _ := clone.FindConnection(clone.Node(2).Unwrap(), "e")
+	forward.Attributes["cable"] = "B7"
+
+	backward, _ := clone.FindConnection(clone.Node(4).Unwrap(), "a")
+	if cable, _ := graphs.Attribute[string](backward.Attributes, "cable"); cable != "B7" {
+		t.Fatalf("expected cloned connections in both directions to share their attributes, but got %v", backward.Attributes)
+	}
+
+	original, _ := graph.FindConnection(graph.Node(2).Unwrap(), "e")
+	if cable, _ := graphs.Attribute[string](original.Attributes, "cable"); cable != "A2" {
+		t.Fatalf("expected original attributes to be unchanged, but got %v", original.Attributes)
+	}
+}
+
+func TestRequiredStates(t *testing.T) {
+	graph := graphs.NewGraph[string, string]()
+
+	point := graphs.NewNode[string, string]("W1")
+	point.ConnectBiWith("head", "main", graphs.Transition{State: "straight"})
+	point.ConnectBiWith("head", "diversion", graphs.Transition{State: "diverging", Weight: 5})
+
+	signal := graphs.NewNode[string, string]("S1")
+	signal.ConnectBi("a", "b")
+
+	straight := graphs.NewNode[string, string]("T1")
+	straight.ConnectBi("a", "b")
+
+	diverging := graphs.NewNode[string, string]("T2")
+	diverging.ConnectBi("a", "b")
+
+	graph.AddNode(point)
+	graph.AddNode(signal)
+	graph.AddNode(straight)
+	graph.AddNode(diverging)
+
+	graph.ConnectRefBi("S1", "b", "W1", "head")
+	graph.ConnectRefBi("W1", "main", "T1", "a")
+	graph.ConnectRefBi("W1", "diversion", "T2", "a")
+
+	paths, _ := graph.FindRef("S1", "b", "T2", "b")
+	if len(paths) != 1 {
+		t.Fatalf("expected 1 path, but got %v", paths)
+	}
+
+	states := graphs.RequiredStates(paths[0])
+	if len(states) != 1 || states[0].Node != "W1" || states[0].State != "diverging" {
+		t.Fatalf("expected W1 to be required in state 'diverging', but got %v", states)
+	}
+
+	_, cost, _ := graph.ShortestPathRef("S1", "b", "T2", "b", nil)
+	if cost != 7 {
+		t.Fatalf("expected cost 7 including the weight of the transition, but got %v", cost)
+	}
+}
@@ -0,0 +1,361 @@
+package graphs
+
+import (
+	"cmp"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+)
+
+const graphMLNamespace = "http://graphml.graphdrawing.org/xmlns"
+
+const (
+	graphMLInner = "inner"
+	graphMLOuter = "outer"
+)
+
+// graphMLReserved lists the keys used to describe connections themselves, so
+// attributes must not be named like them.
+var graphMLReserved = []string{"kind", "state", "weight"}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	Xmlns   string       `xml:"xmlns,attr,omitempty"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	Id   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	Id          string        `xml:"id,attr,omitempty"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	Id    string        `xml:"id,attr"`
+	Ports []graphMLPort `xml:"port"`
+}
+
+type graphMLPort struct {
+	Name string `xml:"name,attr"`
+}
+
+type graphMLEdge struct {
+	Id         string        `xml:"id,attr,omitempty"`
+	Directed   string        `xml:"directed,attr,omitempty"`
+	Source     string        `xml:"source,attr"`
+	SourcePort string        `xml:"sourceport,attr"`
+	Target     string        `xml:"target,attr"`
+	TargetPort string        `xml:"targetport,attr"`
+	Data       []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// data returns the value of the data with the given name. Keys are resolved to
+// their names by keys, as tools like yEd or Gephi rename them. Undeclared keys
+// are taken as names themselves.
+func (edge *graphMLEdge) data(keys map[string]graphMLKey, name string) (string, bool) {
+	for _, data := range edge.Data {
+		if key, ok := keys[data.Key]; ok && key.Name == name || !ok && data.Key == name {
+			return data.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// graphMLType returns the GraphML type of an attribute value. Values of other
+// types than bool, int, int64, float32, float64 and string are written as
+// strings.
+func graphMLType(value any) string {
+	switch value.(type) {
+	case bool:
+		return "boolean"
+	case int:
+		return "int"
+	case int64:
+		return "long"
+	case float32:
+		return "float"
+	case float64:
+		return "double"
+	default:
+		return "string"
+	}
+}
+
+// parseGraphMLValue converts the value of an attribute back to the type it was
+// written with.
+func parseGraphMLValue(value string, typ string) (any, error) {
+	switch typ {
+	case "boolean":
+		return strconv.ParseBool(value)
+	case "int":
+		return strconv.Atoi(value)
+	case "long":
+		return strconv.ParseInt(value, 10, 64)
+	case "float":
+		f, err := strconv.ParseFloat(value, 32)
+		return float32(f), err
+	case "double":
+		return strconv.ParseFloat(value, 64)
+	default:
+		return value, nil
+	}
+}
+
+// WriteGraphML writes the graph as GraphML. Every node lists its ports, inner
+// connections are written as edges from a node to itself with the kind
+// "inner", outer connections as edges with the kind "outer". Attributes of
+// connections are written as data with a key per name and type, see
+// graphMLType. Attributes must not be named kind, state or weight, and node IDs
+// as well as the ports of a node must be distinct when formatted with fmt.Sprint.
+func (graph *Graph[O, P]) WriteGraphML(w io.Writer) error {
+	doc := &graphML{
+		Xmlns: graphMLNamespace,
+		Keys: []graphMLKey{
+			{"kind", "edge", "kind", "string"},
+			{"state", "edge", "state", "string"},
+			{"weight", "edge", "weight", "double"},
+		},
+		Graph: graphMLGraph{"graph", "directed", []graphMLNode{}, []graphMLEdge{}},
+	}
+
+	// Attributes with the same name but values of different types get keys of
+	// their own, so that every value is read back with its type.
+	type attributeKey struct {
+		name string
+		typ  string
+	}
+
+	attributeKeys := map[attributeKey]string{}
+	for _, connection := range graph.connections {
+		for name, value := range connection.Attributes {
+			if slices.Contains(graphMLReserved, name) {
+				return fmt.Errorf("graphml: attribute %s of connection %s clashes with a reserved key", name, connection)
+			}
+
+			attributeKeys[attributeKey{name, graphMLType(value)}] = ""
+		}
+	}
+
+	sorted := slices.SortedFunc(maps.Keys(attributeKeys), func(a, b attributeKey) int {
+		return cmp.Or(cmp.Compare(a.name, b.name), cmp.Compare(a.typ, b.typ))
+	})
+
+	for i, key := range sorted {
+		attributeKeys[key] = fmt.Sprintf("a%d", i)
+		doc.Keys = append(doc.Keys, graphMLKey{attributeKeys[key], "edge", key.name, key.typ})
+	}
+
+	// IDs and ports are written as text, so distinct values must not be
+	// written the same way or they would be merged when reading the graph.
+	nodeIds := map[string]O{}
+
+	for id, node := range graph.Nodes() {
+		element := graphMLNode{fmt.Sprint(id), []graphMLPort{}}
+		if other, ok := nodeIds[element.Id]; ok {
+			return fmt.Errorf("graphml: nodes %#v and %#v are both written as %s", other, id, element.Id)
+		}
+		nodeIds[element.Id] = id
+
+		portNames := map[string]P{}
+		for _, port := range graph.allPorts(node) {
+			name := fmt.Sprint(port)
+			if other, ok := portNames[name]; ok {
+				return fmt.Errorf("graphml: ports %#v and %#v of node %s are both written as %s", other, port, element.Id, name)
+			}
+			portNames[name] = port
+
+			element.Ports = append(element.Ports, graphMLPort{name})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, element)
+
+		for from, to := range node.InnerConnections() {
+			edge := graphMLEdge{fmt.Sprintf("e%d", len(doc.Graph.Edges)), "", element.Id, fmt.Sprint(from), element.Id, fmt.Sprint(to), []graphMLData{{"kind", graphMLInner}}}
+
+			transition, _ := node.Transition(from, to)
+			if transition.State != "" {
+				edge.Data = append(edge.Data, graphMLData{"state", transition.State})
+			}
+
+			if transition.Weight != 0 {
+				edge.Data = append(edge.Data, graphMLData{"weight", strconv.FormatFloat(transition.Weight, 'g', -1, 64)})
+			}
+
+			doc.Graph.Edges = append(doc.Graph.Edges, edge)
+		}
+	}
+
+	for _, connection := range graph.connections {
+		edge := graphMLEdge{
+			fmt.Sprintf("e%d", len(doc.Graph.Edges)),
+			"",
+			fmt.Sprint(connection.FromNode.id),
+			fmt.Sprint(connection.FromPort),
+			fmt.Sprint(connection.ToNode.id),
+			fmt.Sprint(connection.ToPort),
+			[]graphMLData{{"kind", graphMLOuter}},
+		}
+
+		for _, name := range slices.Sorted(maps.Keys(connection.Attributes)) {
+			value := connection.Attributes[name]
+			edge.Data = append(edge.Data, graphMLData{attributeKeys[attributeKey{name, graphMLType(value)}], fmt.Sprint(value)})
+		}
+
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// ReadGraphML reads a graph written by WriteGraphML. Node IDs and ports are
+// converted by the given functions. Edges without a kind are read as outer
+// connections, so that edges drawn in other tools are kept. Undirected edges
+// connect their ports in both directions. Data of outer edges other than kind,
+// state and weight is read as attributes.
+func ReadGraphML[O, P comparable](r io.Reader, parseId func(string) (O, error), parsePort func(string) (P, error)) (*Graph[O, P], error) {
+	doc := &graphML{}
+	if err := xml.NewDecoder(r).Decode(doc); err != nil {
+		return nil, fmt.Errorf("graphml: %s", err)
+	}
+
+	keys := map[string]graphMLKey{}
+	for _, key := range doc.Keys {
+		if key.For != "edge" && key.For != "all" {
+			continue
+		}
+
+		if key.Name == "" {
+			key.Name = key.Id
+		}
+		keys[key.Id] = key
+	}
+
+	graph := NewGraph[O, P]()
+	ids := map[string]O{}
+
+	for _, element := range doc.Graph.Nodes {
+		id, err := parseId(element.Id)
+		if err != nil {
+			return nil, fmt.Errorf("graphml: invalid node ID %s: %s", element.Id, err)
+		}
+
+		if err := graph.AddNodeStrict(NewNode[O, P](id)); err != nil {
+			return nil, err
+		}
+
+		ids[element.Id] = id
+	}
+
+	for _, edge := range doc.Graph.Edges {
+		source, ok := ids[edge.Source]
+		if !ok {
+			return nil, fmt.Errorf("graphml: source %s of edge %s not found", edge.Source, edge.Id)
+		}
+
+		target, ok := ids[edge.Target]
+		if !ok {
+			return nil, fmt.Errorf("graphml: target %s of edge %s not found", edge.Target, edge.Id)
+		}
+
+		if edge.SourcePort == "" || edge.TargetPort == "" {
+			return nil, fmt.Errorf("graphml: edge %s does not connect ports", edge.Id)
+		}
+
+		from, err := parsePort(edge.SourcePort)
+		if err != nil {
+			return nil, fmt.Errorf("graphml: invalid port %s of edge %s: %s", edge.SourcePort, edge.Id, err)
+		}
+
+		to, err := parsePort(edge.TargetPort)
+		if err != nil {
+			return nil, fmt.Errorf("graphml: invalid port %s of edge %s: %s", edge.TargetPort, edge.Id, err)
+		}
+
+		// Undirected loops from a port to itself are connected only once.
+		directed := edge.Directed == "true" || edge.Directed == "" && doc.Graph.EdgeDefault != "undirected" || source == target && from == to
+
+		kind, _ := edge.data(keys, "kind")
+		switch kind {
+		case graphMLInner:
+			if source != target {
+				return nil, fmt.Errorf("graphml: inner edge %s connects different nodes %s and %s", edge.Id, edge.Source, edge.Target)
+			}
+
+			transition := Transition{}
+			transition.State, _ = edge.data(keys, "state")
+
+			if weight, ok := edge.data(keys, "weight"); ok {
+				transition.Weight, err = strconv.ParseFloat(weight, 64)
+				if err != nil {
+					return nil, fmt.Errorf("graphml: invalid weight %s of edge %s: %s", weight, edge.Id, err)
+				}
+			}
+
+			if directed {
+				graph.nodes[source].ConnectWith(from, to, transition)
+			} else {
+				graph.nodes[source].ConnectBiWith(from, to, transition)
+			}
+		case graphMLOuter, "":
+			var attributes Attributes
+			for _, data := range edge.Data {
+				key, ok := keys[data.Key]
+				if !ok || slices.Contains(graphMLReserved, key.Name) {
+					continue
+				}
+
+				value, err := parseGraphMLValue(data.Value, key.Type)
+				if err != nil {
+					return nil, fmt.Errorf("graphml: invalid value %s of %s of edge %s: %s", data.Value, key.Name, edge.Id, err)
+				}
+
+				if attributes == nil {
+					attributes = Attributes{}
+				}
+				attributes[key.Name] = value
+			}
+
+			connect := graph.ConnectRefWith
+			if !directed {
+				connect = graph.ConnectRefBiWith
+			}
+
+			if err := connect(source, from, target, to, attributes); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("graphml: unknown kind %s of edge %s", kind, edge.Id)
+		}
+	}
+
+	return graph, nil
+}
@@ -0,0 +1,196 @@
+package graphs_test
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+func parseString(s string) (string, error) {
+	return s, nil
+}
+
+func TestGraphMLRoundTrip(t *testing.T) {
+	graph := MakeGraph()
+	graph.Node(5).Unwrap().ConnectWith("c", "c", graphs.Transition{State: "reverse", Weight: 2.5})
+	graph.ConnectRefWith(6, "b", 1, "a", graphs.Attributes{"length": 120, "cable": "A2", "electrified": true, "gradient": 1.5})
+
+	var first strings.Builder
+	if err := graph.WriteGraphML(&first); err != nil {
+		t.Fatalf("error when writing GraphML: %s", err)
+	}
+
+	read, err := graphs.ReadGraphML(strings.NewReader(first.String()), strconv.Atoi, parseString)
+	if err != nil {
+		t.Fatalf("error when reading GraphML: %s", err)
+	}
+
+	var second strings.Builder
+	if err := read.WriteGraphML(&second); err != nil {
+		t.Fatalf("error when writing GraphML: %s", err)
+	}
+
+	if first.String() != second.String() {
+		t.Fatalf("round trip changed GraphML:\n%s\nvs.\n%s", first.String(), second.String())
+	}
+
+	for id, node := range graph.Nodes() {
+		other := read.Node(id).Unwrap()
+
+		if !slices.Equal(slices.Collect(node.Ports()), slices.Collect(other.Ports())) {
+			t.Fatalf("ports of node %d differ: %v vs. %v", id, slices.Collect(node.Ports()), slices.Collect(other.Ports()))
+		}
+
+		for from, to := range node.InnerConnections() {
+			expected, _ := node.Transition(from, to)
+			actual, ok := other.Transition(from, to)
+			if !ok || actual != expected {
+				t.Fatalf("inner connection %s -> %s of node %d differs: %v vs. %v", from, to, id, expected, actual)
+			}
+		}
+	}
+
+	expected := slices.Collect(graph.Connections())
+	actual := slices.Collect(read.Connections())
+	if fmt.Sprint(expected) != fmt.Sprint(actual) {
+		t.Fatalf("connections differ: %v vs. %v", expected, actual)
+	}
+
+	connection, _ := read.FindConnection(read.Node(6).Unwrap(), "b")
+	if length, ok := graphs.Attribute[int](connection.Attributes, "length"); !ok || length != 120 {
+		t.Fatalf("expected length 120, but got %v", connection.Attributes)
+	}
+
+	if electrified, ok := graphs.Attribute[bool](connection.Attributes, "electrified"); !ok || !electrified {
+		t.Fatalf("expected connection to be electrified, but got %v", connection.Attributes)
+	}
+
+	if gradient, ok := graphs.Attribute[float64](connection.Attributes, "gradient"); !ok || gradient != 1.5 {
+		t.Fatalf("expected gradient 1.5, but got %v", connection.Attributes)
+	}
+
+	paths, err := read.FindRef(1, "b", 6, "b")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths in read graph, but got %d: %v", len(paths), paths)
+	}
+}
+
+func TestWriteGraphMLReservedAttribute(t *testing.T) {
+	graph := MakeGraph()
+	graph.ConnectRefWith(6, "b", 1, "a", graphs.Attributes{"kind": "cable"})
+
+	var builder strings.Builder
+	if err := graph.WriteGraphML(&builder); err == nil {
+		t.Fatalf("expected error for attribute named like a reserved key")
+	}
+}
+
+func TestWriteGraphMLDuplicateIds(t *testing.T) {
+	graph := graphs.NewGraph[any, any]()
+	graph.AddNode(graphs.NewNode[any, any](1))
+	graph.AddNode(graphs.NewNode[any, any]("1"))
+
+	var builder strings.Builder
+	if err := graph.WriteGraphML(&builder); err == nil {
+		t.Fatalf("expected error for node IDs written the same way")
+	}
+
+	node := graphs.NewNode[any, any]("A")
+	node.Connect(1, "1")
+
+	graph = graphs.NewGraph[any, any]()
+	graph.AddNode(node)
+
+	if err := graph.WriteGraphML(&builder); err == nil {
+		t.Fatalf("expected error for ports written the same way")
+	}
+}
+
+func TestReadGraphML(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <graph edgedefault="directed">
+    <node id="A"><port name="out"/></node>
+    <node id="B"><port name="in"/><port name="out"/></node>
+    <edge source="A" sourceport="out" target="B" targetport="in"/>
+    <edge source="B" sourceport="in" target="B" targetport="out">
+      <data key="kind">inner</data>
+    </edge>
+  </graph>
+</graphml>`
+
+	graph, err := graphs.ReadGraphML(strings.NewReader(doc), parseString, parseString)
+	if err != nil {
+		t.Fatalf("error when reading GraphML: %s", err)
+	}
+
+	if _, ok := graph.FindConnection(graph.Node("A").Unwrap(), "out"); !ok {
+		t.Fatalf("expected edge without kind to be read as outer connection")
+	}
+
+	if next := graph.Node("B").Unwrap().Next("in"); !slices.Equal(next, []string{"out"}) {
+		t.Fatalf("expected inner connection in -> out, but got %v", next)
+	}
+
+	if _, err := graphs.ReadGraphML(strings.NewReader(strings.Replace(doc, `target="B" targetport="in"`, `target="C" targetport="in"`, 1)), parseString, parseString); err == nil {
+		t.Fatalf("expected error for unknown target node")
+	}
+}
+
+func TestReadGraphMLRenamedKeys(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="d0" for="edge" attr.name="kind" attr.type="string"/>
+  <key id="d1" for="edge" attr.name="state" attr.type="string"/>
+  <key id="d2" for="edge" attr.name="weight" attr.type="double"/>
+  <key id="d3" for="edge" attr.name="length" attr.type="int"/>
+  <graph edgedefault="undirected">
+    <node id="A"><port name="out"/></node>
+    <node id="B"><port name="in"/><port name="out"/></node>
+    <edge source="A" sourceport="out" target="B" targetport="in">
+      <data key="d3">120</data>
+    </edge>
+    <edge source="B" sourceport="in" target="B" targetport="out">
+      <data key="d0">inner</data>
+      <data key="d1">straight</data>
+      <data key="d2">2</data>
+    </edge>
+    <edge source="B" sourceport="out" target="B" targetport="out" directed="true">
+      <data key="d0">inner</data>
+    </edge>
+  </graph>
+</graphml>`
+
+	graph, err := graphs.ReadGraphML(strings.NewReader(doc), parseString, parseString)
+	if err != nil {
+		t.Fatalf("error when reading GraphML: %s", err)
+	}
+
+	b := graph.Node("B").Unwrap()
+	for _, ports := range [][2]string{{"in", "out"}, {"out", "in"}} {
+		if transition, ok := b.Transition(ports[0], ports[1]); !ok || transition.State != "straight" || transition.Weight != 2 {
+			t.Fatalf("expected undirected inner connection %s -> %s in state 'straight', but got %+v", ports[0], ports[1], transition)
+		}
+	}
+
+	if next := b.Next("out"); !slices.Equal(next, []string{"in", "out"}) {
+		t.Fatalf("expected directed loop to be connected once, but got %v", next)
+	}
+
+	backward, ok := graph.FindConnection(b, "in")
+	if !ok || backward.ToNode.Id() != "A" {
+		t.Fatalf("expected undirected edge to connect B back to A, but got %v", backward)
+	}
+
+	if length, ok := graphs.Attribute[int](backward.Attributes, "length"); !ok || length != 120 {
+		t.Fatalf("expected length 120, but got %v", backward.Attributes)
+	}
+}
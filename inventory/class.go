@@ -6,6 +6,8 @@ type Class[C, P comparable] struct {
 	Ports            map[P]*Port[P]
 	Connections      []*Connection[P]
 	PathConstruction *PathConstruction[P]
+
+	portOrder []P // Order of the ports as parsed, used when serializing
 }
 
 func NewClass[C, P comparable](id C, label string) *Class[C, P] {
@@ -15,18 +17,21 @@ func NewClass[C, P comparable](id C, label string) *Class[C, P] {
 		map[P]*Port[P]{},
 		[]*Connection[P]{},
 		nil,
+		[]P{},
 	}
 }
 
 type Port[P comparable] struct {
-	Id    P      `yaml:"id"`
-	Label string `yaml:"label"`
+	Id    P      `yaml:"id" json:"id"`
+	Label string `yaml:"label" json:"label"`
 }
 
 type Connection[P comparable] struct {
-	From          P    `yaml:"from"`
-	To            P    `yaml:"to"`
-	Bidirectional bool `yaml:"bidirectional"`
+	From          P       `yaml:"from" json:"from"`
+	To            P       `yaml:"to" json:"to"`
+	Bidirectional bool    `yaml:"bidirectional,omitempty" json:"bidirectional,omitempty"`
+	State         string  `yaml:"state,omitempty" json:"state,omitempty"`
+	Weight        float64 `yaml:"weight,omitempty" json:"weight,omitempty"`
 }
 
 type PathConstruction[P comparable] struct {
@@ -0,0 +1,65 @@
+package inventory
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Format is the file format of a model.
+type Format int
+
+const (
+	FormatYAML Format = iota
+	FormatJSON
+)
+
+// DetectFormat tells the format of a model by the extension of its file name.
+// Without a known extension, content starting with an object or array is JSON,
+// anything else YAML.
+func DetectFormat(filename string, content []byte) Format {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	}
+
+	trimmed := bytes.TrimSpace(content)
+	if len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') {
+		return FormatJSON
+	}
+
+	return FormatYAML
+}
+
+// Decode decodes a model in the given format.
+func Decode(r io.Reader, format Format, v any) error {
+	if format == FormatJSON {
+		return json.NewDecoder(r).Decode(v)
+	}
+
+	return yaml.NewDecoder(r).Decode(v)
+}
+
+// Encode encodes a model in the given format, indented by two spaces.
+func Encode(w io.Writer, format Format, v any) error {
+	if format == FormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(v)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
@@ -0,0 +1,26 @@
+package inventory_test
+
+import (
+	"testing"
+
+	"github.com/yannickkirschen/graphs/inventory"
+)
+
+func TestDetectFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		content  string
+		expected inventory.Format
+	}{
+		{"a.json", "", inventory.FormatJSON},
+		{"a.YML", "{}", inventory.FormatYAML},
+		{"a", "  \n{\"classes\": []}", inventory.FormatJSON},
+		{"a.txt", "classes: []", inventory.FormatYAML},
+	}
+
+	for _, test := range tests {
+		if format := inventory.DetectFormat(test.filename, []byte(test.content)); format != test.expected {
+			t.Fatalf("expected format %d for %s, but got %d", test.expected, test.filename, format)
+		}
+	}
+}
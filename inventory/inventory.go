@@ -7,17 +7,37 @@ import (
 )
 
 type Inventory[O, C, P comparable] struct {
-	classes map[C]*Class[C, P]
-	objects map[O]*Object[O, C, P]
+	classes     map[C]*Class[C, P]
+	classOrder  []C
+	objects     map[O]*Object[O, C, P]
+	objectOrder []O
 }
 
 func NewInventory[O, C, P comparable]() *Inventory[O, C, P] {
 	return &Inventory[O, C, P]{
 		map[C]*Class[C, P]{},
+		[]C{},
 		map[O]*Object[O, C, P]{},
+		[]O{},
 	}
 }
 
+func (inventory *Inventory[O, C, P]) addClass(class *Class[C, P]) {
+	if _, ok := inventory.classes[class.Id]; !ok {
+		inventory.classOrder = append(inventory.classOrder, class.Id)
+	}
+
+	inventory.classes[class.Id] = class
+}
+
+func (inventory *Inventory[O, C, P]) addObject(object *Object[O, C, P]) {
+	if _, ok := inventory.objects[object.Id]; !ok {
+		inventory.objectOrder = append(inventory.objectOrder, object.Id)
+	}
+
+	inventory.objects[object.Id] = object
+}
+
 func (inventory *Inventory[O, C, P]) GetClass(id C) optional.Option[*Class[C, P]] {
 	class, ok := inventory.classes[id]
 	if !ok {
@@ -38,8 +58,8 @@ func (inventory *Inventory[O, C, P]) GetObject(id O) optional.Option[*Object[O,
 
 func (inventory *Inventory[O, C, P]) Classes() iter.Seq2[C, *Class[C, P]] {
 	return func(yield func(C, *Class[C, P]) bool) {
-		for k, v := range inventory.classes {
-			if !yield(k, v) {
+		for _, k := range inventory.classOrder {
+			if !yield(k, inventory.classes[k]) {
 				return
 			}
 		}
@@ -48,8 +68,8 @@ func (inventory *Inventory[O, C, P]) Classes() iter.Seq2[C, *Class[C, P]] {
 
 func (inventory *Inventory[O, C, P]) Objects() iter.Seq2[O, *Object[O, C, P]] {
 	return func(yield func(O, *Object[O, C, P]) bool) {
-		for k, v := range inventory.objects {
-			if !yield(k, v) {
+		for _, k := range inventory.objectOrder {
+			if !yield(k, inventory.objects[k]) {
 				return
 			}
 		}
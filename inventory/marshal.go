@@ -0,0 +1,149 @@
+package inventory
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ToModel converts the inventory back to its model. Classes and objects keep
+// the order in which they have been added. Ports keep the order in which they
+// have been parsed, ports added later on follow sorted by their IDs.
+func (inventory *Inventory[O, C, P]) ToModel() (*Model[O, C, P], error) {
+	model := &Model[O, C, P]{[]*ClassModel[C, P]{}, []*ObjectModel[O, C, P]{}}
+
+	for _, class := range inventory.Classes() {
+		model.Classes = append(model.Classes, class.ToModel())
+	}
+
+	for _, object := range inventory.Objects() {
+		objectModel, err := object.ToModel()
+		if err != nil {
+			return nil, err
+		}
+
+		model.Objects = append(model.Objects, objectModel)
+	}
+
+	return model, nil
+}
+
+func (class *Class[C, P]) ToModel() *ClassModel[C, P] {
+	model := &ClassModel[C, P]{class.Id, class.Label, []*Port[P]{}, slices.Clone(class.Connections), nil}
+
+	for _, id := range class.sortedPorts() {
+		model.Ports = append(model.Ports, class.Ports[id])
+	}
+
+	if class.PathConstruction != nil {
+		model.PathConstruction = &PathConstructionModel[P]{}
+
+		if class.PathConstruction.Start != nil {
+			start := class.PathConstruction.Start.Id
+			model.PathConstruction.Start = &start
+		}
+
+		if class.PathConstruction.End != nil {
+			end := class.PathConstruction.End.Id
+			model.PathConstruction.End = &end
+		}
+	}
+
+	return model
+}
+
+// sortedPorts lists the IDs of the ports in parsing order followed by all other
+// ports sorted by their IDs, see compareIds.
+func (class *Class[C, P]) sortedPorts() []P {
+	ids := []P{}
+	seen := map[P]bool{}
+
+	for _, id := range class.portOrder {
+		if _, ok := class.Ports[id]; ok && !seen[id] {
+			ids = append(ids, id)
+			seen[id] = true
+		}
+	}
+
+	others := []P{}
+	for id := range class.Ports {
+		if !seen[id] {
+			others = append(others, id)
+		}
+	}
+
+	slices.SortFunc(others, compareIds)
+	return append(ids, others...)
+}
+
+// compareIds orders numbers and strings by their values and IDs of any other
+// kind by their string representation.
+func compareIds[P comparable](a, b P) int {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.IsValid() && vb.IsValid() && va.Kind() == vb.Kind() {
+		switch {
+		case va.CanInt():
+			return cmp.Compare(va.Int(), vb.Int())
+		case va.CanUint():
+			return cmp.Compare(va.Uint(), vb.Uint())
+		case va.CanFloat():
+			return cmp.Compare(va.Float(), vb.Float())
+		case va.Kind() == reflect.String:
+			return strings.Compare(va.String(), vb.String())
+		}
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func (object *Object[O, C, P]) ToModel() (*ObjectModel[O, C, P], error) {
+	model := &ObjectModel[O, C, P]{Id: object.Id, Label: object.Label}
+
+	if object.Class != nil {
+		model.ClassRef = object.Class.Id
+	}
+
+	if object.Spec.IsSome() {
+		if err := model.Spec.Encode(object.Spec.Unwrap()); err != nil {
+			return nil, fmt.Errorf("cannot encode spec of object %s (ID %v): %s", object.Label, object.Id, err)
+		}
+	} else {
+		model.Spec = object.rawSpec
+	}
+
+	return model, nil
+}
+
+// Marshal serializes the inventory to YAML.
+func (inventory *Inventory[O, C, P]) Marshal() ([]byte, error) {
+	return inventory.marshal(FormatYAML)
+}
+
+// WriteFile writes the inventory to a file, as JSON if its name ends with
+// .json and as YAML otherwise.
+func (inventory *Inventory[O, C, P]) WriteFile(filename string) error {
+	data, err := inventory.marshal(DetectFormat(filename, nil))
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, 0o644)
+}
+
+func (inventory *Inventory[O, C, P]) marshal(format Format) ([]byte, error) {
+	model, err := inventory.ToModel()
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	if err := Encode(&buffer, format, model); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
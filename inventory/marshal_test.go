@@ -0,0 +1,160 @@
+package inventory_test
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yannickkirschen/graphs/inventory"
+)
+
+const inventoryYAML = `classes:
+  - id: point
+    label: Point
+    ports:
+      - id: head
+        label: Head
+      - id: main
+        label: Main
+    connections:
+      - from: head
+        to: main
+        bidirectional: true
+        state: straight
+        weight: 1.5
+    pathConstruction:
+      start: head
+      end: main
+objects:
+  - id: W1
+    label: Point 1
+    class: point
+    spec:
+      speed: 40
+      gradient: 2.5
+      heated: true
+      motor: M1
+`
+
+type MotorSpec struct {
+	Speed    int     `yaml:"speed"`
+	Gradient float64 `yaml:"gradient"`
+	Heated   bool    `yaml:"heated"`
+	Motor    string  `yaml:"motor"`
+}
+
+func MakeInventory(t *testing.T) *inventory.Inventory[string, string, string] {
+	inv, err := inventory.ParseWithSpec[string, string, string](io.NopCloser(strings.NewReader(inventoryYAML)), inventory.SpecMap{"Point": reflect.TypeOf(MotorSpec{})})
+	if err != nil {
+		t.Fatalf("error when parsing inventory: %s", err)
+	}
+
+	return inv
+}
+
+func TestClassToModel(t *testing.T) {
+	inv := MakeInventory(t)
+	class := inv.GetClass("point").Unwrap()
+
+	class.Ports["diversion"] = &inventory.Port[string]{Id: "diversion", Label: "Diversion"}
+	class.Ports["b"] = &inventory.Port[string]{Id: "b", Label: "B"}
+	class.PathConstruction.End = nil
+
+	model := class.ToModel()
+
+	ids := []string{}
+	for _, port := range model.Ports {
+		ids = append(ids, port.Id)
+	}
+
+	if !reflect.DeepEqual(ids, []string{"head", "main", "b", "diversion"}) {
+		t.Fatalf("expected parsed ports followed by sorted new ports, but got %v", ids)
+	}
+
+	if *model.PathConstruction.Start != "head" || model.PathConstruction.End != nil {
+		t.Fatalf("expected path construction to start at head without end, but got %+v", model.PathConstruction)
+	}
+
+	model.Connections[0] = &inventory.Connection[string]{From: "main", To: "head"}
+	if class.Connections[0].From != "head" {
+		t.Fatalf("expected model not to share connections with class, but got %+v", class.Connections[0])
+	}
+
+	data, err := inv.Marshal()
+	if err != nil {
+		t.Fatalf("error when marshalling inventory: %s", err)
+	}
+
+	parsed, err := inventory.Parse[string, string, string](io.NopCloser(strings.NewReader(string(data))))
+	if err != nil {
+		t.Fatalf("error when parsing marshalled inventory: %s\n%s", err, data)
+	}
+
+	construction := parsed.GetClass("point").Unwrap().PathConstruction
+	if construction.Start.Id != "head" || construction.End != nil {
+		t.Fatalf("expected path construction without end to survive, but got %+v", construction)
+	}
+
+	numbered := inventory.NewClass[string, int]("track", "Track")
+	for _, id := range []int{10, 9, 2} {
+		numbered.Ports[id] = &inventory.Port[int]{Id: id}
+	}
+
+	if ports := numbered.ToModel().Ports; ports[0].Id != 2 || ports[1].Id != 9 || ports[2].Id != 10 {
+		t.Fatalf("expected numeric ports to be sorted by value, but got %v, %v and %v", ports[0].Id, ports[1].Id, ports[2].Id)
+	}
+}
+
+func TestWriteFileJSON(t *testing.T) {
+	inv := MakeInventory(t)
+
+	filename := filepath.Join(t.TempDir(), "inventory.json")
+	if err := inv.WriteFile(filename); err != nil {
+		t.Fatalf("error when writing inventory: %s", err)
+	}
+
+	read, err := inventory.ParseFileWithSpec[string, string, string](filename, inventory.SpecMap{"Point": reflect.TypeOf(MotorSpec{})})
+	if err != nil {
+		t.Fatalf("error when reading inventory: %s", err)
+	}
+
+	spec := read.GetObject("W1").Unwrap().Spec.Unwrap()
+	if *spec.(*MotorSpec) != (MotorSpec{40, 2.5, true, "M1"}) {
+		t.Fatalf("expected spec to survive JSON round trip, but got %+v", spec)
+	}
+
+	connection := read.GetClass("point").Unwrap().Connections[0]
+	if connection.State != "straight" || connection.Weight != 1.5 || !connection.Bidirectional {
+		t.Fatalf("expected connection to survive JSON round trip, but got %+v", connection)
+	}
+
+	expected, _ := inv.Marshal()
+	actual, _ := read.Marshal()
+	if string(expected) != string(actual) {
+		t.Fatalf("round trip changed inventory:\n%s\nvs.\n%s", expected, actual)
+	}
+}
+
+func TestMarshalUntypedSpec(t *testing.T) {
+	inv, err := inventory.Parse[string, string, string](io.NopCloser(strings.NewReader(inventoryYAML)))
+	if err != nil {
+		t.Fatalf("error when parsing inventory: %s", err)
+	}
+
+	data, err := inv.Marshal()
+	if err != nil {
+		t.Fatalf("error when marshalling inventory: %s", err)
+	}
+
+	parsed, err := inventory.ParseWithSpec[string, string, string](io.NopCloser(strings.NewReader(string(data))), inventory.SpecMap{"Point": reflect.TypeOf(MotorSpec{})})
+	if err != nil {
+		t.Fatalf("error when parsing marshalled inventory: %s\n%s", err, data)
+	}
+
+	spec := parsed.GetObject("W1").Unwrap().Spec.Unwrap()
+	if *spec.(*MotorSpec) != (MotorSpec{40, 2.5, true, "M1"}) {
+		t.Fatalf("expected spec without type to survive round trip, but got %+v", spec)
+	}
+}
@@ -1,6 +1,8 @@
 package inventory
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -10,16 +12,16 @@ import (
 )
 
 type Model[O, C, P comparable] struct {
-	Classes []*ClassModel[C, P]     `yaml:"classes"`
-	Objects []*ObjectModel[O, C, P] `yaml:"objects"`
+	Classes []*ClassModel[C, P]     `yaml:"classes" json:"classes"`
+	Objects []*ObjectModel[O, C, P] `yaml:"objects" json:"objects"`
 }
 
 type ClassModel[C, P comparable] struct {
-	Id               C                         `yaml:"id"`
-	Label            string                    `yaml:"label"`
-	Ports            []*Port[P]                `yaml:"ports"`
-	Connections      []*Connection[P]          `yaml:"connections"`
-	PathConstruction *PathConstructionModel[P] `yaml:"pathConstruction"`
+	Id               C                         `yaml:"id" json:"id"`
+	Label            string                    `yaml:"label" json:"label"`
+	Ports            []*Port[P]                `yaml:"ports" json:"ports"`
+	Connections      []*Connection[P]          `yaml:"connections,omitempty" json:"connections,omitempty"`
+	PathConstruction *PathConstructionModel[P] `yaml:"pathConstruction,omitempty" json:"pathConstruction,omitempty"`
 }
 
 func (model *ClassModel[O, P]) ToClass() (*Class[O, P], error) {
@@ -31,6 +33,7 @@ func (model *ClassModel[O, P]) ToClass() (*Class[O, P], error) {
 		}
 
 		class.Ports[port.Id] = port
+		class.portOrder = append(class.portOrder, port.Id)
 	}
 
 	var pathConstruction *PathConstruction[P]
@@ -47,20 +50,28 @@ func (model *ClassModel[O, P]) ToClass() (*Class[O, P], error) {
 	return class, nil
 }
 
+// PathConstructionModel refers to the ports paths start and end at. Either of
+// them may be omitted for classes that only start or only end paths.
 type PathConstructionModel[P comparable] struct {
-	Start P `yaml:"start"`
-	End   P `yaml:"end"`
+	Start *P `yaml:"start,omitempty" json:"start,omitempty"`
+	End   *P `yaml:"end,omitempty" json:"end,omitempty"`
 }
 
 func (model *PathConstructionModel[P]) ToPathConstruction(ports map[P]*Port[P]) (*PathConstruction[P], error) {
-	start, ok := ports[model.Start]
-	if !ok {
-		return nil, fmt.Errorf("path construction parsing error: start port ref %v does not exist", model.Start)
+	var start, end *Port[P]
+
+	if model.Start != nil {
+		var ok bool
+		if start, ok = ports[*model.Start]; !ok {
+			return nil, fmt.Errorf("path construction parsing error: start port ref %v does not exist", *model.Start)
+		}
 	}
 
-	end, ok := ports[model.End]
-	if !ok {
-		return nil, fmt.Errorf("path construction parsing error: end port ref %v does not exist", model.End)
+	if model.End != nil {
+		var ok bool
+		if end, ok = ports[*model.End]; !ok {
+			return nil, fmt.Errorf("path construction parsing error: end port ref %v does not exist", *model.End)
+		}
 	}
 
 	return &PathConstruction[P]{
@@ -70,10 +81,65 @@ func (model *PathConstructionModel[P]) ToPathConstruction(ports map[P]*Port[P])
 }
 
 type ObjectModel[O, C, P comparable] struct {
-	Id       O         `yaml:"id"`
-	Label    string    `yaml:"label"`
-	ClassRef C         `yaml:"class"`
-	Spec     yaml.Node `yaml:"spec"`
+	Id       O         `yaml:"id" json:"id"`
+	Label    string    `yaml:"label" json:"label"`
+	ClassRef C         `yaml:"class" json:"class"`
+	Spec     yaml.Node `yaml:"spec,omitempty" json:"-"`
+}
+
+type objectModelJSON[O, C comparable] struct {
+	Id       O               `json:"id"`
+	Label    string          `json:"label"`
+	ClassRef C               `json:"class"`
+	Spec     json.RawMessage `json:"spec,omitempty"`
+}
+
+// MarshalJSON writes the spec as a plain JSON value.
+func (model *ObjectModel[O, C, P]) MarshalJSON() ([]byte, error) {
+	aux := &objectModelJSON[O, C]{model.Id, model.Label, model.ClassRef, nil}
+
+	if !model.Spec.IsZero() {
+		var spec any
+		if err := model.Spec.Decode(&spec); err != nil {
+			return nil, fmt.Errorf("cannot encode spec of object %s: %s", model.Label, err)
+		}
+
+		raw, err := json.Marshal(spec)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode spec of object %s: %s", model.Label, err)
+		}
+
+		aux.Spec = raw
+	}
+
+	return json.Marshal(aux)
+}
+
+// UnmarshalJSON reads the spec into a YAML node, so that it is decoded by
+// ParseSpec like a spec read from YAML. This works as JSON is valid YAML.
+func (model *ObjectModel[O, C, P]) UnmarshalJSON(data []byte) error {
+	aux := &objectModelJSON[O, C]{}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	model.Id = aux.Id
+	model.Label = aux.Label
+	model.ClassRef = aux.ClassRef
+	model.Spec = yaml.Node{}
+
+	if len(aux.Spec) > 0 {
+		var document yaml.Node
+		if err := yaml.Unmarshal(aux.Spec, &document); err != nil {
+			return fmt.Errorf("cannot decode spec of object %s: %s", aux.Label, err)
+		}
+
+		if document.Kind == yaml.DocumentNode && len(document.Content) > 0 {
+			model.Spec = *document.Content[0]
+		}
+	}
+
+	return nil
 }
 
 func (model *ObjectModel[O, C, P]) ToObject(classes map[C]*Class[C, P], specTypes SpecMap) (*Object[O, C, P], error) {
@@ -96,6 +162,10 @@ func (model *ObjectModel[O, C, P]) ToObject(classes map[C]*Class[C, P], specType
 		}
 	}
 
+	if object.Spec.IsNone() {
+		object.rawSpec = model.Spec
+	}
+
 	return object, nil
 }
 
@@ -112,7 +182,7 @@ func (model *Model[O, C, P]) ToInventory(specTypes SpecMap) (*Inventory[O, C, P]
 			return nil, err
 		}
 
-		inv.classes[class.Id] = class
+		inv.addClass(class)
 	}
 
 	for _, objectModel := range model.Objects {
@@ -121,7 +191,7 @@ func (model *Model[O, C, P]) ToInventory(specTypes SpecMap) (*Inventory[O, C, P]
 			return nil, err
 		}
 
-		inv.objects[object.Id] = object
+		inv.addObject(object)
 	}
 
 	return inv, nil
@@ -132,8 +202,20 @@ func Parse[O, C, P comparable](r io.ReadCloser) (*Inventory[O, C, P], error) {
 }
 
 func ParseWithSpec[O, C, P comparable](r io.ReadCloser, specTypes SpecMap) (*Inventory[O, C, P], error) {
+	return parse[O, C, P](r, FormatYAML, specTypes)
+}
+
+func ParseJSON[O, C, P comparable](r io.ReadCloser) (*Inventory[O, C, P], error) {
+	return ParseJSONWithSpec[O, C, P](r, nil)
+}
+
+func ParseJSONWithSpec[O, C, P comparable](r io.ReadCloser, specTypes SpecMap) (*Inventory[O, C, P], error) {
+	return parse[O, C, P](r, FormatJSON, specTypes)
+}
+
+func parse[O, C, P comparable](r io.Reader, format Format, specTypes SpecMap) (*Inventory[O, C, P], error) {
 	var model *Model[O, C, P]
-	if err := yaml.NewDecoder(r).Decode(&model); err != nil {
+	if err := Decode(r, format, &model); err != nil {
 		return nil, fmt.Errorf("error parsing input: %s", err)
 	}
 
@@ -144,11 +226,12 @@ func ParseFile[O, C, P comparable](filename string) (*Inventory[O, C, P], error)
 	return ParseFileWithSpec[O, C, P](filename, nil)
 }
 
+// ParseFileWithSpec parses a YAML or JSON file, see DetectFormat.
 func ParseFileWithSpec[O, C, P comparable](filename string, specTypes SpecMap) (*Inventory[O, C, P], error) {
-	f, err := os.Open(filename)
+	content, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("error opening %s: %s", filename, err)
 	}
 
-	return ParseWithSpec[O, C, P](f, specTypes)
+	return parse[O, C, P](bytes.NewReader(content), DetectFormat(filename, content), specTypes)
 }
@@ -0,0 +1,40 @@
+package inventory_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/yannickkirschen/graphs/inventory"
+	"gopkg.in/yaml.v3"
+)
+
+type PointSpec struct {
+	Speed int    `yaml:"speed"`
+	Motor string `yaml:"motor"`
+}
+
+func TestObjectModelJSON(t *testing.T) {
+	var model inventory.ObjectModel[string, string, string]
+	if err := yaml.Unmarshal([]byte("id: W1\nlabel: Point 1\nclass: point\nspec:\n  speed: 40\n"), &model); err != nil {
+		t.Fatalf("error when parsing YAML: %s", err)
+	}
+
+	data, err := json.Marshal(&model)
+	if err != nil {
+		t.Fatalf("error when encoding JSON: %s", err)
+	}
+
+	if string(data) != `{"id":"W1","label":"Point 1","class":"point","spec":{"speed":40}}` {
+		t.Fatalf("unexpected JSON: %s", data)
+	}
+
+	var decoded inventory.ObjectModel[string, string, string]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("error when decoding JSON: %s", err)
+	}
+
+	var spec PointSpec
+	if err := decoded.Spec.Decode(&spec); err != nil || spec.Speed != 40 {
+		t.Fatalf("expected spec to survive JSON round trip, but got %v (%v)", spec, err)
+	}
+}
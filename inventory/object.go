@@ -3,6 +3,7 @@ package inventory
 import (
 	"github.com/moznion/go-optional"
 	"github.com/yannickkirschen/graphs"
+	"gopkg.in/yaml.v3"
 )
 
 type Object[O, C, P comparable] struct {
@@ -10,6 +11,8 @@ type Object[O, C, P comparable] struct {
 	Label string
 	Class *Class[C, P]
 	Spec  optional.Option[any]
+
+	rawSpec yaml.Node // Spec as read if there is no type for it, written back by ToModel
 }
 
 func NewObject[O, C, P comparable](id O, label string) *Object[O, C, P] {
@@ -18,6 +21,7 @@ func NewObject[O, C, P comparable](id O, label string) *Object[O, C, P] {
 		label,
 		nil,
 		optional.None[any](),
+		yaml.Node{},
 	}
 }
 
@@ -25,10 +29,11 @@ func (object *Object[O, C, P]) ToGraphNode() *graphs.Node[O, P] {
 	node := graphs.NewNode[O, P](object.Id)
 
 	for _, connection := range object.Class.Connections {
+		transition := graphs.Transition{State: connection.State, Weight: connection.Weight}
 		if connection.Bidirectional {
-			node.ConnectBi(connection.From, connection.To)
+			node.ConnectBiWith(connection.From, connection.To, transition)
 		} else {
-			node.Connect(connection.From, connection.To)
+			node.ConnectWith(connection.From, connection.To, transition)
 		}
 	}
 
@@ -0,0 +1,141 @@
+package graphs
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// MermaidOptions configure the output of WriteMermaid. All fields are
+// optional.
+type MermaidOptions[O, P comparable] struct {
+	Direction  string                                // Flowchart direction, LR if empty
+	Label      func(node *Node[O, P]) string         // Label of a node, its ID if nil
+	PortLabel  func(node *Node[O, P], port P) string // Label of a port, the port if nil
+	Group      func(node *Node[O, P]) string         // Subgraph to put a node in, none if empty
+	GroupLabel func(node *Node[O, P]) string         // Caption of the subgraph of a node, the group if nil
+	Ports      bool                                  // Draw ports and inner connections inside a subgraph per node
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart. Outer connections are
+// labelled with their ports unless ports are drawn as nodes of their own.
+// Bidirectional pairs are drawn as a single undirected link. Options may be
+// nil.
+func (graph *Graph[O, P]) WriteMermaid(w io.Writer, options *MermaidOptions[O, P]) error {
+	if options == nil {
+		options = &MermaidOptions[O, P]{}
+	}
+
+	direction := options.Direction
+	if direction == "" {
+		direction = "LR"
+	}
+
+	label := options.Label
+	if label == nil {
+		label = func(node *Node[O, P]) string {
+			return fmt.Sprint(node.id)
+		}
+	}
+
+	portLabel := options.PortLabel
+	if portLabel == nil {
+		portLabel = func(node *Node[O, P], port P) string {
+			return fmt.Sprint(port)
+		}
+	}
+
+	ids := map[O]string{}
+	ports := map[O][]P{}
+	groups := []string{}
+	captions := map[string]string{}
+	members := map[string][]*Node[O, P]{}
+
+	for id, node := range graph.Nodes() {
+		ids[id] = fmt.Sprintf("n%d", len(ids))
+		ports[id] = graph.allPorts(node)
+
+		group := ""
+		if options.Group != nil {
+			group = options.Group(node)
+		}
+
+		if _, ok := members[group]; !ok {
+			groups = append(groups, group)
+			captions[group] = group
+			if options.GroupLabel != nil {
+				captions[group] = options.GroupLabel(node)
+			}
+		}
+		members[group] = append(members[group], node)
+	}
+
+	portId := func(node *Node[O, P], port P) string {
+		return fmt.Sprintf("%sp%d", ids[node.id], slices.Index(ports[node.id], port))
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "flowchart %s\n", direction)
+
+	for i, group := range groups {
+		indent := "\t"
+		if group != "" {
+			fmt.Fprintf(out, "\tsubgraph g%d[\"%s\"]\n", i, mermaidEscape(captions[group]))
+			indent = "\t\t"
+		}
+
+		for _, node := range members[group] {
+			if !options.Ports {
+				fmt.Fprintf(out, "%s%s[\"%s\"]\n", indent, ids[node.id], mermaidEscape(label(node)))
+				continue
+			}
+
+			fmt.Fprintf(out, "%ssubgraph %s[\"%s\"]\n", indent, ids[node.id], mermaidEscape(label(node)))
+			for _, port := range ports[node.id] {
+				fmt.Fprintf(out, "%s\t%s([\"%s\"])\n", indent, portId(node, port), mermaidEscape(portLabel(node, port)))
+			}
+
+			seen := map[portPair[P]]bool{}
+			for from, to := range node.InnerConnections() {
+				if seen[portPair[P]{from, to}] {
+					continue
+				}
+
+				link := "-->"
+				if _, ok := node.Transition(to, from); ok && from != to {
+					link = "---"
+					seen[portPair[P]{to, from}] = true
+				}
+
+				fmt.Fprintf(out, "%s\t%s %s %s\n", indent, portId(node, from), link, portId(node, to))
+			}
+			fmt.Fprintf(out, "%send\n", indent)
+		}
+
+		if group != "" {
+			fmt.Fprintln(out, "\tend")
+		}
+	}
+
+	for _, edge := range graph.edges() {
+		link := "-->"
+		if edge.bidirectional {
+			link = "---"
+		}
+
+		if options.Ports {
+			fmt.Fprintf(out, "\t%s %s %s\n", portId(edge.FromNode, edge.FromPort), link, portId(edge.ToNode, edge.ToPort))
+		} else {
+			ends := fmt.Sprintf("%s - %s", portLabel(edge.FromNode, edge.FromPort), portLabel(edge.ToNode, edge.ToPort))
+			fmt.Fprintf(out, "\t%s %s|\"%s\"| %s\n", ids[edge.FromNode.id], link, mermaidEscape(ends), ids[edge.ToNode.id])
+		}
+	}
+
+	return out.Flush()
+}
+
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", "\n", "<br>").Replace(s)
+}
@@ -0,0 +1,36 @@
+package graphs_test
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteMermaid(t *testing.T) {
+	graph := MakeGraph()
+	graph.ConnectRef(6, "b", 1, "a")
+
+	var builder strings.Builder
+	if err := graph.WriteMermaid(&builder, nil); err != nil {
+		t.Fatalf("error when writing Mermaid: %s", err)
+	}
+
+	expected := `flowchart LR
+	n0["1"]
+	n1["2"]
+	n2["3"]
+	n3["4"]
+	n4["5"]
+	n5["6"]
+	n0 ---|"b - c"| n1
+	n1 ---|"d - a"| n2
+	n1 ---|"e - a"| n3
+	n2 ---|"b - b"| n4
+	n3 ---|"b - c"| n4
+	n4 ---|"a - a"| n5
+	n5 -->|"b - a"| n0
+`
+
+	if builder.String() != expected {
+		t.Fatalf("unexpected Mermaid output:\n%s", builder.String())
+	}
+}
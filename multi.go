@@ -0,0 +1,84 @@
+package graphs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// PathGroup holds the paths from one port to another found by FindMulti.
+type PathGroup[O, P comparable] struct {
+	From  PortRef[O, P]
+	To    PortRef[O, P]
+	Paths [][]*PathSegment[O, P]
+}
+
+// FindMulti finds all paths from any of the sources to any of the targets.
+// Instead of searching every pair on its own, there is a single search per
+// source that collects the paths to all targets at once. The result holds a
+// group for every pair of source and target, ordered by source first.
+func (graph *Graph[O, P]) FindMulti(sources, targets []PortRef[O, P]) ([]*PathGroup[O, P], error) {
+	groups, _, err := graph.FindMultiContext(context.Background(), sources, targets, FindOptions[O, P]{})
+	return groups, err
+}
+
+// FindMultiContext works like FindMulti, but stops as soon as ctx is done or
+// one of the limits in options is reached. The limits apply to the search of
+// every source on its own. The returned status tells why the first incomplete
+// search ended.
+func (graph *Graph[O, P]) FindMultiContext(ctx context.Context, sources, targets []PortRef[O, P], options FindOptions[O, P]) ([]*PathGroup[O, P], FindStatus, error) {
+	for _, ref := range slices.Concat(sources, targets) {
+		if _, ok := graph.nodes[ref.Node]; !ok {
+			return nil, FindExhausted, fmt.Errorf("graph: ref %v not found and thus cannot find paths", ref.Node)
+		}
+	}
+
+	if !options.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, options.Deadline)
+		defer cancel()
+	}
+
+	targetIdx := map[portKey[O, P]][]int{}
+	targetSet := map[portKey[O, P]]bool{}
+	for i, target := range targets {
+		key := portKey[O, P]{target.Node, target.Port}
+		targetIdx[key] = append(targetIdx[key], i)
+		targetSet[key] = true
+	}
+
+	groups := make([]*PathGroup[O, P], 0, len(sources)*len(targets))
+	status := FindExhausted
+
+	for _, source := range sources {
+		offset := len(groups)
+		for _, target := range targets {
+			groups = append(groups, &PathGroup[O, P]{source, target, [][]*PathSegment[O, P]{}})
+		}
+
+		data := newDFSData[O, P](ctx, options, nil)
+		data.Targets = targetSet
+		data.Reached = func(key portKey[O, P], path []*PathSegment[O, P]) bool {
+			for _, i := range targetIdx[key] {
+				groups[offset+i].Paths = append(groups[offset+i].Paths, path)
+			}
+			return true
+		}
+
+		// The connection only tells where the search starts, as the targets
+		// replace its end.
+		node := graph.nodes[source.Node]
+		var entry P
+		graph.dfsFind(data, NewConnection(node, source.Port, node, source.Port), entry, nil)
+
+		if result := data.Result(); status == FindExhausted {
+			status = result
+		}
+
+		if ctx.Err() != nil {
+			return groups, status, ctx.Err()
+		}
+	}
+
+	return groups, status, nil
+}
@@ -0,0 +1,81 @@
+package graphs_test
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+func TestFindMulti(t *testing.T) {
+	graph := MakeGraph()
+
+	sources := []graphs.PortRef[int, string]{{Node: 1, Port: "b"}, {Node: 6, Port: "a"}}
+	targets := []graphs.PortRef[int, string]{{Node: 4, Port: "b"}, {Node: 3, Port: "b"}, {Node: 1, Port: "a"}}
+
+	groups, err := graph.FindMulti(sources, targets)
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(groups) != len(sources)*len(targets) {
+		t.Fatalf("expected %d groups, but got %d", len(sources)*len(targets), len(groups))
+	}
+
+	for i, group := range groups {
+		source, target := sources[i/len(targets)], targets[i%len(targets)]
+		if group.From != source || group.To != target {
+			t.Fatalf("expected group %d to be %v -> %v, but got %v -> %v", i, source, target, group.From, group.To)
+		}
+
+		expected, err := graph.FindRef(source.Node, source.Port, target.Node, target.Port)
+		if err != nil {
+			t.Fatalf("error when finding paths: %s", err)
+		}
+
+		if fmt.Sprint(group.Paths) != fmt.Sprint(expected) {
+			t.Fatalf("expected paths %v -> %v to be %v, but got %v", source, target, expected, group.Paths)
+		}
+	}
+
+	if len(groups[5].Paths) != 2 {
+		t.Fatalf("expected 2 paths from 6 to 1, but got %v", groups[5].Paths)
+	}
+}
+
+func TestFindMultiAllPorts(t *testing.T) {
+	graph := MakeGraph()
+
+	refs := []graphs.PortRef[int, string]{}
+	for id, node := range graph.Nodes() {
+		for port := range node.Ports() {
+			refs = append(refs, graphs.PortRef[int, string]{Node: id, Port: port})
+		}
+	}
+
+	groups, err := graph.FindMulti(refs, refs)
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	for _, group := range groups {
+		expected, err := graph.FindRef(group.From.Node, group.From.Port, group.To.Node, group.To.Port)
+		if err != nil {
+			t.Fatalf("error when finding paths: %s", err)
+		}
+
+		if fmt.Sprint(group.Paths) != fmt.Sprint(expected) {
+			t.Fatalf("expected paths %v -> %v to be %v, but got %v", group.From, group.To, expected, group.Paths)
+		}
+	}
+
+	// Ports of the same node are connected by the node itself.
+	idx := slices.IndexFunc(groups, func(group *graphs.PathGroup[int, string]) bool {
+		return group.From == graphs.PortRef[int, string]{Node: 3, Port: "b"} && group.To == graphs.PortRef[int, string]{Node: 3, Port: "a"}
+	})
+
+	if len(groups[idx].Paths) != 1 {
+		t.Fatalf("expected a path from 3.b to 3.a, but got %v", groups[idx].Paths)
+	}
+}
@@ -0,0 +1,133 @@
+package graphs
+
+import (
+	"container/heap"
+	"fmt"
+
+	"github.com/moznion/go-optional"
+)
+
+// Reached is a node found by Nearest along with the cheapest path to it. The
+// last segment of the path enters the reached node but does not leave it.
+type Reached[O, P comparable] struct {
+	Node *Node[O, P]
+	Path []*PathSegment[O, P]
+	Hops int // Number of outer connections taken
+	Cost float64
+}
+
+// Nearest expands outward from a port and returns the nodes for which match
+// holds, ordered by the cost of the cheapest path to them. Paths are built by
+// the same rules as in ShortestPath, so they never enter a node twice. A limit
+// of 0 or less returns all matching nodes. The start node itself is never
+// returned.
+func (graph *Graph[O, P]) Nearest(fromNode *Node[O, P], fromPort P, match func(node *Node[O, P]) bool, limit int, costs *Costs[O, P]) ([]*Reached[O, P], error) {
+	reached := []*Reached[O, P]{}
+
+	if !graph.occupancy.passable(fromNode.id, fromPort, fromPort, true) {
+		return reached, nil
+	}
+
+	var entry P
+	index := nodeIndex[O]{}
+	startKey := portKey[O, P]{fromNode.id, fromPort}
+
+	// Every label keeps the nodes passed so far, see looplessHops.
+	first := &dijkstraLabel[O, P]{hop[O, P]{fromNode, entry, fromPort, nil}, 0, nil, false, bitset{}.with(index.of(fromNode.id))}
+	exits := map[portKey[O, P]][]*dijkstraLabel[O, P]{startKey: {first}}
+	arrivals := map[portKey[O, P]][]*dijkstraLabel[O, P]{}
+	reported := map[O]bool{fromNode.id: true}
+
+	queue := &dijkstraQueue[O, P]{}
+	heap.Push(queue, &dijkstraItem[O, P]{startKey, 0, 0, false, first})
+	seq := 1
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(*dijkstraItem[O, P])
+
+		label := item.label
+		if label.done {
+			continue
+		}
+
+		label.done = true
+
+		if item.arrival {
+			node := label.hop.node
+
+			if !reported[node.id] && match(node) {
+				reported[node.id] = true
+				reached = append(reached, label.reached())
+
+				if limit > 0 && len(reached) >= limit {
+					return reached, nil
+				}
+			}
+
+			for _, port := range node.Next(label.hop.entry) {
+				if !graph.occupancy.passable(node.id, label.hop.entry, port, false) {
+					continue
+				}
+
+				weight := costs.inner(node, label.hop.entry, port)
+				if weight < 0 {
+					return nil, fmt.Errorf("graph: negative cost %v for %v: %v -> %v", weight, node.id, label.hop.entry, port)
+				}
+
+				exit := &dijkstraLabel[O, P]{hop[O, P]{node, label.hop.entry, port, label.hop.via}, label.cost + weight, label.previous, false, label.visited}
+				key := portKey[O, P]{node.id, port}
+
+				var added bool
+				if exits[key], added = addLabel(exits[key], exit); added {
+					heap.Push(queue, &dijkstraItem[O, P]{key, exit.cost, seq, false, exit})
+					seq++
+				}
+			}
+
+			continue
+		}
+
+		for _, connection := range graph.outgoing[item.key] {
+			node := index.of(connection.ToNode.id)
+			if label.visited.has(node) || graph.occupancy.PortOccupied(connection.ToNode.id, connection.ToPort) {
+				continue
+			}
+
+			weight := costs.connection(connection)
+			if weight < 0 {
+				return nil, fmt.Errorf("graph: negative cost %v for %s", weight, connection)
+			}
+
+			arrival := &dijkstraLabel[O, P]{hop[O, P]{connection.ToNode, connection.ToPort, entry, connection}, label.cost + weight, label, false, label.visited.with(node)}
+			key := portKey[O, P]{connection.ToNode.id, connection.ToPort}
+
+			var added bool
+			if arrivals[key], added = addLabel(arrivals[key], arrival); added {
+				heap.Push(queue, &dijkstraItem[O, P]{key, arrival.cost, seq, true, arrival})
+				seq++
+			}
+		}
+	}
+
+	return reached, nil
+}
+
+func (graph *Graph[O, P]) NearestRef(fromRef O, fromPort P, match func(node *Node[O, P]) bool, limit int, costs *Costs[O, P]) ([]*Reached[O, P], error) {
+	fromNode, ok := graph.nodes[fromRef]
+	if !ok {
+		return nil, fmt.Errorf("graph: from ref %v not found and thus cannot find nearest nodes", fromRef)
+	}
+
+	return graph.Nearest(fromNode, fromPort, match, limit, costs)
+}
+
+// reached builds the result for an arrival label, whose previous labels are
+// the exits taken before.
+func (label *dijkstraLabel[O, P]) reached() *Reached[O, P] {
+	hops, _, _ := label.previous.unwind()
+
+	path := hopsToPath(hops)
+	path = append(path, &PathSegment[O, P]{optional.Some(label.hop.entry), label.hop.node, optional.None[P](), label.hop.via})
+
+	return &Reached[O, P]{label.hop.node, path, len(hops), label.cost}
+}
@@ -0,0 +1,64 @@
+package graphs_test
+
+import (
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+func TestNearest(t *testing.T) {
+	graph := MakeGraph()
+
+	reached, err := graph.NearestRef(1, "b", func(node *graphs.Node[int, string]) bool {
+		return node.Id() >= 4
+	}, 0, nil)
+	if err != nil {
+		t.Fatalf("error when finding nearest nodes: %s", err)
+	}
+
+	if len(reached) != 3 {
+		t.Fatalf("expected 3 reached nodes, but got %d: %v", len(reached), reached)
+	}
+
+	expected := []struct {
+		id   int
+		hops int
+	}{{4, 2}, {5, 3}, {6, 4}}
+
+	for i, e := range expected {
+		if reached[i].Node.Id() != e.id || reached[i].Hops != e.hops || reached[i].Cost != float64(e.hops) {
+			t.Fatalf("expected node %d after %d hops at position %d, but got %d after %d hops (cost %v)", e.id, e.hops, i, reached[i].Node.Id(), reached[i].Hops, reached[i].Cost)
+		}
+
+		path := reached[i].Path
+		if len(path) != e.hops+1 || path[len(path)-1].Middle.Id() != e.id || path[len(path)-1].Right.IsSome() {
+			t.Fatalf("expected path to end by entering %d, but got %v", e.id, path)
+		}
+	}
+
+	reached, err = graph.NearestRef(1, "b", func(node *graphs.Node[int, string]) bool {
+		return node.Id() >= 4
+	}, 1, nil)
+	if err != nil {
+		t.Fatalf("error when finding nearest nodes: %s", err)
+	}
+
+	if len(reached) != 1 || reached[0].Node.Id() != 4 {
+		t.Fatalf("expected only node 4 to be reached, but got %v", reached)
+	}
+}
+
+func TestNearestNoReentry(t *testing.T) {
+	graph := MakeLoopGraph()
+
+	reached, err := graph.NearestRef("A", "out", func(node *graphs.Node[string, string]) bool {
+		return node.Id() == "T"
+	}, 0, nil)
+	if err != nil {
+		t.Fatalf("error when finding nearest nodes: %s", err)
+	}
+
+	if len(reached) != 1 || reached[0].Cost != 12 || pathIds(reached[0].Path) != "AXT" {
+		t.Fatalf("expected T to be reached directly via X with cost 12, but got %v", reached)
+	}
+}
@@ -1,14 +1,32 @@
 package graphs
 
-import "fmt"
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+)
 
 type Node[O, P comparable] struct {
 	id          O
+	ports       []P
 	connections map[P][]P
+	transitions map[portPair[P]]Transition
+}
+
+// Transition describes an inner connection from one port of a node to another.
+type Transition struct {
+	State  string  // State the node has to be in, like the position of a point
+	Weight float64 // Cost of passing the node this way
+}
+
+type portPair[P comparable] struct {
+	from P
+	to   P
 }
 
 func NewNode[O, P comparable](id O) *Node[O, P] {
-	return &Node[O, P]{id, map[P][]P{}}
+	return &Node[O, P]{id, []P{}, map[P][]P{}, map[portPair[P]]Transition{}}
 }
 
 func (node *Node[O, P]) Id() O {
@@ -22,6 +40,8 @@ func (node *Node[O, P]) Connect(from, to P) {
 	}
 
 	node.connections[from] = append(connection, to)
+	node.addPort(from)
+	node.addPort(to)
 }
 
 func (node *Node[O, P]) ConnectBi(from, to P) {
@@ -29,10 +49,127 @@ func (node *Node[O, P]) ConnectBi(from, to P) {
 	node.Connect(to, from)
 }
 
+func (node *Node[O, P]) ConnectWith(from, to P, transition Transition) {
+	node.Connect(from, to)
+	node.transitions[portPair[P]{from, to}] = transition
+}
+
+func (node *Node[O, P]) ConnectBiWith(from, to P, transition Transition) {
+	node.ConnectWith(from, to, transition)
+	node.ConnectWith(to, from, transition)
+}
+
+// Transition returns the metadata of the inner connection from one port to
+// another and whether the ports are connected at all.
+func (node *Node[O, P]) Transition(from, to P) (Transition, bool) {
+	if !slices.Contains(node.connections[from], to) {
+		return Transition{}, false
+	}
+
+	return node.transitions[portPair[P]{from, to}], true
+}
+
+// Disconnect removes the inner connection from one port to another and reports
+// whether it existed.
+func (node *Node[O, P]) Disconnect(from, to P) bool {
+	connection, ok := node.connections[from]
+	if !ok {
+		return false
+	}
+
+	idx := slices.Index(connection, to)
+	if idx < 0 {
+		return false
+	}
+
+	connection = slices.Delete(connection, idx, idx+1)
+	if !slices.Contains(connection, to) {
+		delete(node.transitions, portPair[P]{from, to})
+	}
+
+	if len(connection) == 0 {
+		delete(node.connections, from)
+	} else {
+		node.connections[from] = connection
+	}
+
+	node.removePort(from)
+	node.removePort(to)
+	return true
+}
+
+// DisconnectBi removes the inner connections between two ports in both
+// directions. Nothing is removed unless both of them exist.
+func (node *Node[O, P]) DisconnectBi(from, to P) bool {
+	if !slices.Contains(node.connections[from], to) || !slices.Contains(node.connections[to], from) {
+		return false
+	}
+
+	node.Disconnect(from, to)
+	node.Disconnect(to, from)
+	return true
+}
+
 func (node *Node[O, P]) Next(port P) []P {
 	return node.connections[port]
 }
 
+// Ports iterates over all ports used by inner connections in the order they
+// have first been connected.
+func (node *Node[O, P]) Ports() iter.Seq[P] {
+	return func(yield func(P) bool) {
+		for _, port := range node.ports {
+			if !yield(port) {
+				return
+			}
+		}
+	}
+}
+
+// InnerConnections iterates over all pairs of ports the node connects.
+func (node *Node[O, P]) InnerConnections() iter.Seq2[P, P] {
+	return func(yield func(P, P) bool) {
+		for _, from := range node.ports {
+			for _, to := range node.connections[from] {
+				if !yield(from, to) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (node *Node[O, P]) addPort(port P) {
+	if !slices.Contains(node.ports, port) {
+		node.ports = append(node.ports, port)
+	}
+}
+
+// removePort forgets a port once no inner connection uses it anymore.
+func (node *Node[O, P]) removePort(port P) {
+	if _, ok := node.connections[port]; ok {
+		return
+	}
+
+	for _, connection := range node.connections {
+		if slices.Contains(connection, port) {
+			return
+		}
+	}
+
+	idx := slices.Index(node.ports, port)
+	node.ports = slices.Delete(node.ports, idx, idx+1)
+}
+
+func (node *Node[O, P]) clone() *Node[O, P] {
+	clone := &Node[O, P]{node.id, slices.Clone(node.ports), map[P][]P{}, maps.Clone(node.transitions)}
+	for from, to := range node.connections {
+		clone.connections[from] = slices.Clone(to)
+	}
+
+	return clone
+}
+
 func (node *Node[O, P]) String() string {
 	return fmt.Sprintf("Node<%v>", node.id)
 }
@@ -1,6 +1,7 @@
 package graphs_test
 
 import (
+	"slices"
 	"testing"
 
 	"github.com/yannickkirschen/graphs"
@@ -67,3 +68,113 @@ func TestDiversion(t *testing.T) {
 		t.Fatalf("expected next ports to be ['head'], but got %v", nextPorts)
 	}
 }
+
+func TestDisconnectPorts(t *testing.T) {
+	node := MakeNode()
+
+	if !node.DisconnectBi("head", "diversion") {
+		t.Fatalf("expected connection between 'head' and 'diversion' to be removed")
+	}
+
+	if node.Disconnect("head", "diversion") {
+		t.Fatalf("expected connection between 'head' and 'diversion' to be gone")
+	}
+
+	nextPorts := node.Next("head")
+	if len(nextPorts) != 1 || nextPorts[0] != "main" {
+		t.Fatalf("expected next ports to be ['main'], but got %v", nextPorts)
+	}
+
+	if nextPorts := node.Next("diversion"); len(nextPorts) != 0 {
+		t.Fatalf("expected no next ports, but got %v", nextPorts)
+	}
+}
+
+func TestDisconnectBiOneWay(t *testing.T) {
+	node := MakeNode()
+	node.Connect("main", "diversion")
+
+	if node.DisconnectBi("main", "diversion") {
+		t.Fatalf("expected one-way connection not to be removed in both directions")
+	}
+
+	if nextPorts := node.Next("main"); !slices.Contains(nextPorts, "diversion") {
+		t.Fatalf("expected connection from 'main' to 'diversion' to be kept, but got next ports %v", nextPorts)
+	}
+}
+
+func TestPorts(t *testing.T) {
+	node := MakeNode()
+
+	ports := slices.Collect(node.Ports())
+	if !slices.Equal(ports, []string{"head", "main", "diversion"}) {
+		t.Fatalf("expected ports ['head', 'main', 'diversion'], but got %v", ports)
+	}
+
+	node.DisconnectBi("head", "diversion")
+
+	ports = slices.Collect(node.Ports())
+	if !slices.Equal(ports, []string{"head", "main"}) {
+		t.Fatalf("expected ports ['head', 'main'], but got %v", ports)
+	}
+}
+
+func TestInnerConnections(t *testing.T) {
+	node := MakeNode()
+
+	count := 0
+	for from, to := range node.InnerConnections() {
+		if !slices.Contains(node.Next(from), to) {
+			t.Fatalf("unexpected inner connection %s -> %s", from, to)
+		}
+		count++
+	}
+
+	if count != 4 {
+		t.Fatalf("expected 4 inner connections, but got %d", count)
+	}
+}
+
+func TestTransition(t *testing.T) {
+	node := graphs.NewNode[string, string]("W1")
+	node.ConnectBiWith("head", "main", graphs.Transition{State: "straight", Weight: 1})
+	node.ConnectBiWith("head", "diversion", graphs.Transition{State: "diverging", Weight: 3})
+	node.Connect("main", "diversion")
+
+	transition, ok := node.Transition("diversion", "head")
+	if !ok || transition.State != "diverging" || transition.Weight != 3 {
+		t.Fatalf("expected transition 'diverging' with weight 3, but got %+v", transition)
+	}
+
+	if transition, ok := node.Transition("main", "diversion"); !ok || transition != (graphs.Transition{}) {
+		t.Fatalf("expected transition without metadata, but got %+v", transition)
+	}
+
+	if _, ok := node.Transition("diversion", "main"); ok {
+		t.Fatalf("expected 'diversion' and 'main' not to be connected")
+	}
+
+	node.Disconnect("head", "main")
+	if _, ok := node.Transition("head", "main"); ok {
+		t.Fatalf("expected transition to be removed")
+	}
+}
+
+func TestTransitionDuplicate(t *testing.T) {
+	node := graphs.NewNode[string, string]("W1")
+	node.ConnectWith("head", "main", graphs.Transition{State: "straight", Weight: 1})
+	node.ConnectWith("head", "main", graphs.Transition{State: "straight", Weight: 1})
+
+	if !node.Disconnect("head", "main") {
+		t.Fatalf("expected connection between 'head' and 'main' to be removed")
+	}
+
+	if transition, ok := node.Transition("head", "main"); !ok || transition.State != "straight" {
+		t.Fatalf("expected remaining connection to keep transition 'straight', but got %+v", transition)
+	}
+
+	node.Disconnect("head", "main")
+	if _, ok := node.Transition("head", "main"); ok {
+		t.Fatalf("expected transition to be removed with the last connection")
+	}
+}
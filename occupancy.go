@@ -0,0 +1,151 @@
+package graphs
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// Occupancy marks nodes and ports of a graph as unavailable, for example
+// because they are occupied or out of service. Path searches skip occupied
+// nodes and ports. It is safe for concurrent use, so occupations can be changed
+// while searches are running.
+type Occupancy[O, P comparable] struct {
+	mutex sync.RWMutex
+	nodes map[O]bool
+	ports map[portKey[O, P]]bool
+	count atomic.Int64
+}
+
+func NewOccupancy[O, P comparable]() *Occupancy[O, P] {
+	return &Occupancy[O, P]{
+		nodes: map[O]bool{},
+		ports: map[portKey[O, P]]bool{},
+	}
+}
+
+func (occupancy *Occupancy[O, P]) OccupyNode(id O) {
+	occupancy.mutex.Lock()
+	defer occupancy.mutex.Unlock()
+
+	occupancy.nodes[id] = true
+	occupancy.update()
+}
+
+func (occupancy *Occupancy[O, P]) ReleaseNode(id O) {
+	occupancy.mutex.Lock()
+	defer occupancy.mutex.Unlock()
+
+	delete(occupancy.nodes, id)
+	occupancy.update()
+}
+
+func (occupancy *Occupancy[O, P]) OccupyPort(id O, port P) {
+	occupancy.mutex.Lock()
+	defer occupancy.mutex.Unlock()
+
+	occupancy.ports[portKey[O, P]{id, port}] = true
+	occupancy.update()
+}
+
+func (occupancy *Occupancy[O, P]) ReleasePort(id O, port P) {
+	occupancy.mutex.Lock()
+	defer occupancy.mutex.Unlock()
+
+	delete(occupancy.ports, portKey[O, P]{id, port})
+	occupancy.update()
+}
+
+// Clear releases all nodes and ports.
+func (occupancy *Occupancy[O, P]) Clear() {
+	occupancy.mutex.Lock()
+	defer occupancy.mutex.Unlock()
+
+	clear(occupancy.nodes)
+	clear(occupancy.ports)
+	occupancy.update()
+}
+
+func (occupancy *Occupancy[O, P]) NodeOccupied(id O) bool {
+	if occupancy.empty() {
+		return false
+	}
+
+	occupancy.mutex.RLock()
+	defer occupancy.mutex.RUnlock()
+
+	return occupancy.nodes[id]
+}
+
+// PortOccupied reports whether the port itself or its node is occupied.
+func (occupancy *Occupancy[O, P]) PortOccupied(id O, port P) bool {
+	if occupancy.empty() {
+		return false
+	}
+
+	occupancy.mutex.RLock()
+	defer occupancy.mutex.RUnlock()
+
+	return occupancy.nodes[id] || occupancy.ports[portKey[O, P]{id, port}]
+}
+
+// Blocks reports whether a path passes an occupied node or uses an occupied
+// port.
+func (occupancy *Occupancy[O, P]) Blocks(path []*PathSegment[O, P]) bool {
+	if occupancy.empty() {
+		return false
+	}
+
+	for i, segment := range path {
+		if occupancy.NodeOccupied(segment.Middle.id) {
+			return true
+		}
+
+		// Paths start by leaving their first node, so its entry is not used.
+		if i > 0 && segment.Left.IsSome() && occupancy.PortOccupied(segment.Middle.id, segment.Left.Unwrap()) {
+			return true
+		}
+
+		if segment.Right.IsSome() && occupancy.PortOccupied(segment.Middle.id, segment.Right.Unwrap()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// passable reports whether a node may be passed from entry to exit. The entry
+// is ignored for the first node of a path.
+func (occupancy *Occupancy[O, P]) passable(id O, entry P, exit P, first bool) bool {
+	if occupancy.empty() {
+		return true
+	}
+
+	occupancy.mutex.RLock()
+	defer occupancy.mutex.RUnlock()
+
+	if occupancy.nodes[id] || occupancy.ports[portKey[O, P]{id, exit}] {
+		return false
+	}
+
+	return first || !occupancy.ports[portKey[O, P]{id, entry}]
+}
+
+// clone copies the occupancy, so that snapshots of a graph are not affected by
+// later occupations.
+func (occupancy *Occupancy[O, P]) clone() *Occupancy[O, P] {
+	occupancy.mutex.RLock()
+	defer occupancy.mutex.RUnlock()
+
+	clone := &Occupancy[O, P]{nodes: maps.Clone(occupancy.nodes), ports: maps.Clone(occupancy.ports)}
+	clone.update()
+	return clone
+}
+
+func (occupancy *Occupancy[O, P]) empty() bool {
+	return occupancy.count.Load() == 0
+}
+
+func (occupancy *Occupancy[O, P]) update() {
+	occupancy.count.Store(int64(len(occupancy.nodes) + len(occupancy.ports)))
+}
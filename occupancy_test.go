@@ -0,0 +1,139 @@
+package graphs_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestOccupancy(t *testing.T) {
+	graph := MakeGraph()
+	occupancy := graph.Occupancy()
+
+	occupancy.OccupyNode(3)
+
+	paths, err := graph.FindRef(1, "b", 6, "b")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 1 || paths[0][2].Middle.Id() != 4 {
+		t.Fatalf("expected a single path via 4, but got %v", paths)
+	}
+
+	compiled, err := graph.Compile().FindRef(1, "b", 6, "b")
+	if err != nil {
+		t.Fatalf("error when finding compiled paths: %s", err)
+	}
+
+	if len(compiled) != 1 {
+		t.Fatalf("expected compiled graph to find 1 path, but got %d: %v", len(compiled), compiled)
+	}
+
+	path, _, err := graph.ShortestPathRef(1, "b", 6, "b", nil)
+	if err != nil {
+		t.Fatalf("error when finding shortest path: %s", err)
+	}
+
+	if path[2].Middle.Id() != 4 {
+		t.Fatalf("expected shortest path via 4, but got %v", path)
+	}
+
+	occupancy.OccupyPort(4, "a")
+
+	paths, err = graph.FindRef(1, "b", 6, "b")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 0 {
+		t.Fatalf("expected no paths, but got %v", paths)
+	}
+
+	occupancy.ReleaseNode(3)
+	occupancy.ReleasePort(4, "a")
+
+	paths, err = graph.FindRef(1, "b", 6, "b")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 2 {
+		t.Fatalf("expected 2 paths after release, but got %d: %v", len(paths), paths)
+	}
+}
+
+func TestOccupancyBlocks(t *testing.T) {
+	graph := MakeGraph()
+
+	paths, err := graph.FindRef(1, "b", 6, "b")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	graph.Occupancy().OccupyPort(5, "c")
+
+	blocked := 0
+	for _, path := range paths {
+		if graph.Occupancy().Blocks(path) {
+			blocked++
+		}
+	}
+
+	if blocked != 1 {
+		t.Fatalf("expected 1 blocked path, but got %d", blocked)
+	}
+}
+
+func TestOccupancyCompiledRace(t *testing.T) {
+	graph := MakeGraph()
+	compiled := graph.Compile()
+
+	var wg, ready sync.WaitGroup
+	done := make(chan struct{})
+	errs := make(chan error, 8)
+
+	for range 8 {
+		wg.Add(1)
+		ready.Add(1)
+		go func() {
+			defer wg.Done()
+			ready.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				if paths, _ := compiled.FindRef(1, "b", 6, "b"); len(paths) != 2 {
+					errs <- errors.New("occupancy changed compiled graph")
+					return
+				}
+			}
+		}()
+	}
+
+	ready.Wait()
+	for range 200 {
+		graph.Occupancy().OccupyNode(3)
+		graph.Occupancy().ReleaseNode(3)
+	}
+
+	close(done)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("error when searching concurrently: %s", err)
+	}
+
+	graph.Occupancy().OccupyNode(3)
+	if paths, _ := compiled.FindRef(1, "b", 6, "b"); len(paths) != 2 {
+		t.Fatalf("expected compiled graph to ignore the occupation, but got %v", paths)
+	}
+
+	if paths, _ := graph.Compile().FindRef(1, "b", 6, "b"); len(paths) != 1 {
+		t.Fatalf("expected compiling to take over the occupancy, but got %v", paths)
+	}
+}
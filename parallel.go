@@ -0,0 +1,146 @@
+package graphs
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// ParallelOptions configures FindParallel. Zero values select defaults.
+type ParallelOptions struct {
+	Workers    int // Number of goroutines searching, defaults to GOMAXPROCS
+	SplitDepth int // Depth at which the search tree is split into tasks, chosen automatically if zero
+}
+
+// maxSplitDepth bounds the automatic choice of the split depth.
+const maxSplitDepth = 16
+
+// FindParallel finds the same paths in the same order as Find, but searches
+// the subtrees below the first levels of the search tree concurrently.
+func (graph *Graph[O, P]) FindParallel(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P, options ParallelOptions) [][]*PathSegment[O, P] {
+	workers := options.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	var items []*parallelItem[O, P]
+	var tasks []*parallelItem[O, P]
+
+	if options.SplitDepth > 0 {
+		items, tasks = graph.splitSearch(fromNode, fromPort, toNode, toPort, options.SplitDepth)
+	} else {
+		for depth := 1; depth <= maxSplitDepth; depth++ {
+			items, tasks = graph.splitSearch(fromNode, fromPort, toNode, toPort, depth)
+			if len(tasks) == 0 || len(tasks) >= workers*4 {
+				break
+			}
+		}
+	}
+
+	queue := make(chan *parallelItem[O, P])
+	var wg sync.WaitGroup
+
+	for range min(workers, len(tasks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				graph.runTask(task)
+			}
+		}()
+	}
+
+	for _, task := range tasks {
+		queue <- task
+	}
+
+	close(queue)
+	wg.Wait()
+
+	paths := [][]*PathSegment[O, P]{}
+	for _, item := range items {
+		if item.task {
+			paths = append(paths, item.paths...)
+		} else {
+			paths = append(paths, item.path)
+		}
+	}
+
+	return paths
+}
+
+func (graph *Graph[O, P]) FindParallelRef(fromRef O, fromPort P, toRef O, toPort P, options ParallelOptions) ([][]*PathSegment[O, P], error) {
+	fromNode, ok := graph.nodes[fromRef]
+	if !ok {
+		return nil, fmt.Errorf("graph: from ref %v not found and thus cannot find paths", fromRef)
+	}
+
+	toNode, ok := graph.nodes[toRef]
+	if !ok {
+		return nil, fmt.Errorf("graph: to ref %v not found and thus cannot find paths", toRef)
+	}
+
+	return graph.FindParallel(fromNode, fromPort, toNode, toPort, options), nil
+}
+
+// parallelItem is either a path found while splitting the search tree or a
+// subtree to be searched by a worker.
+type parallelItem[O, P comparable] struct {
+	path []*PathSegment[O, P]
+
+	task        bool
+	visited     []*Node[O, P]
+	currentPath []*PathSegment[O, P]
+	current     *Connection[O, P]
+	entry       P
+	via         *Connection[O, P]
+	paths       [][]*PathSegment[O, P]
+}
+
+// splitSearch runs the search down to depth and records every subtree below as
+// a task, keeping the order in which Find would produce the paths.
+func (graph *Graph[O, P]) splitSearch(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P, depth int) ([]*parallelItem[O, P], []*parallelItem[O, P]) {
+	items := []*parallelItem[O, P]{}
+	tasks := []*parallelItem[O, P]{}
+
+	data := newDFSData(context.Background(), FindOptions[O, P]{}, func(path []*PathSegment[O, P]) bool {
+		items = append(items, &parallelItem[O, P]{path: path})
+		return true
+	})
+
+	data.Split = func(current *Connection[O, P], entry P, via *Connection[O, P]) bool {
+		if len(data.CurrentPath) < depth {
+			return false
+		}
+
+		task := &parallelItem[O, P]{
+			task:        true,
+			visited:     slices.Clone(data.Visited),
+			currentPath: slices.Clone(data.CurrentPath),
+			current:     current,
+			entry:       entry,
+			via:         via,
+		}
+
+		items = append(items, task)
+		tasks = append(tasks, task)
+		return true
+	}
+
+	var entry P
+	graph.dfsFind(data, NewConnection(fromNode, fromPort, toNode, toPort), entry, nil)
+	return items, tasks
+}
+
+func (graph *Graph[O, P]) runTask(task *parallelItem[O, P]) {
+	data := newDFSData(context.Background(), FindOptions[O, P]{}, func(path []*PathSegment[O, P]) bool {
+		task.paths = append(task.paths, path)
+		return true
+	})
+
+	data.Visited = task.visited
+	data.CurrentPath = task.currentPath
+	graph.dfsFind(data, task.current, task.entry, task.via)
+}
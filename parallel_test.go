@@ -0,0 +1,81 @@
+package graphs_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+// MakeLadderGraph builds a graph with a start node S, an end node T and the
+// given number of stages in between. Each stage consists of an upper and a
+// lower node that are both connected to both nodes of the next stage, so there
+// are 2^stages paths from S to T.
+func MakeLadderGraph(stages int) *graphs.Graph[string, string] {
+	graph := graphs.NewGraph[string, string]()
+
+	addNode := func(id string) {
+		node := graphs.NewNode[string, string](id)
+		node.ConnectBi("in", "out")
+		graph.AddNode(node)
+	}
+
+	addNode("S")
+
+	previous := []string{"S"}
+	for i := 0; i <= stages; i++ {
+		current := []string{fmt.Sprintf("U%d", i), fmt.Sprintf("L%d", i)}
+		if i == stages {
+			current = []string{"T"}
+		}
+
+		for _, id := range current {
+			addNode(id)
+		}
+
+		for _, from := range previous {
+			for _, to := range current {
+				graph.ConnectRefBi(from, "out", to, "in")
+			}
+		}
+
+		previous = current
+	}
+
+	return graph
+}
+
+func TestFindParallel(t *testing.T) {
+	graph := MakeLadderGraph(8)
+
+	expected, err := graph.FindRef("S", "out", "T", "out")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(expected) != 256 {
+		t.Fatalf("expected 256 paths, but got %d", len(expected))
+	}
+
+	for _, options := range []graphs.ParallelOptions{{}, {Workers: 1}, {Workers: 4, SplitDepth: 1}, {Workers: 3, SplitDepth: 5}, {Workers: 8, SplitDepth: 20}} {
+		paths, err := graph.FindParallelRef("S", "out", "T", "out", options)
+		if err != nil {
+			t.Fatalf("error when finding paths in parallel with %+v: %s", options, err)
+		}
+
+		if fmt.Sprint(paths) != fmt.Sprint(expected) {
+			t.Fatalf("expected parallel search with %+v to find the same paths as sequential search", options)
+		}
+	}
+}
+
+func TestFindParallelSmall(t *testing.T) {
+	graph := MakeGraph()
+
+	expected, _ := graph.FindRef(1, "b", 4, "b")
+	paths, _ := graph.FindParallelRef(1, "b", 4, "b", graphs.ParallelOptions{Workers: 2})
+
+	if fmt.Sprint(paths) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, but got %v", expected, paths)
+	}
+}
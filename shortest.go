@@ -0,0 +1,506 @@
+package graphs
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/moznion/go-optional"
+)
+
+var ErrNoPath = errors.New("graph: no path found")
+
+// Costs assigns weights to outer connections and inner port transitions. A nil
+// Connection function costs 1 per connection, a nil Inner function uses the
+// weight of the node's Transition, which is 0 unless set.
+type Costs[O, P comparable] struct {
+	Connection func(connection *Connection[O, P]) float64
+	Inner      func(node *Node[O, P], from, to P) float64
+}
+
+func (costs *Costs[O, P]) connection(connection *Connection[O, P]) float64 {
+	if costs == nil || costs.Connection == nil {
+		return 1
+	}
+
+	return costs.Connection(connection)
+}
+
+func (costs *Costs[O, P]) inner(node *Node[O, P], from, to P) float64 {
+	if costs == nil || costs.Inner == nil {
+		transition, _ := node.Transition(from, to)
+		return transition.Weight
+	}
+
+	return costs.Inner(node, from, to)
+}
+
+// ShortestPath searches the cheapest path between two ports with Dijkstra's
+// algorithm. Paths are built by the same rules as in Find, so a node is never
+// entered twice, even if leaving it by another port would be cheaper.
+func (graph *Graph[O, P]) ShortestPath(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P, costs *Costs[O, P]) ([]*PathSegment[O, P], float64, error) {
+	var entry P
+	hops, cumulative, err := graph.dijkstra(hop[O, P]{fromNode, entry, fromPort, nil}, portKey[O, P]{toNode.id, toPort}, costs, nil, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return hopsToPath(hops), cumulative[len(cumulative)-1], nil
+}
+
+func (graph *Graph[O, P]) ShortestPathRef(fromRef O, fromPort P, toRef O, toPort P, costs *Costs[O, P]) ([]*PathSegment[O, P], float64, error) {
+	fromNode, ok := graph.nodes[fromRef]
+	if !ok {
+		return nil, 0, fmt.Errorf("graph: from ref %v not found and thus cannot find paths", fromRef)
+	}
+
+	toNode, ok := graph.nodes[toRef]
+	if !ok {
+		return nil, 0, fmt.Errorf("graph: to ref %v not found and thus cannot find paths", toRef)
+	}
+
+	return graph.ShortestPath(fromNode, fromPort, toNode, toPort, costs)
+}
+
+// KShortestPaths returns up to k paths ordered by their total cost using Yen's
+// algorithm. The costs of the paths are returned alongside. Deviations from a
+// previously found path never re-enter a node of the shared root path.
+func (graph *Graph[O, P]) KShortestPaths(fromNode *Node[O, P], fromPort P, toNode *Node[O, P], toPort P, k int, costs *Costs[O, P]) ([][]*PathSegment[O, P], []float64, error) {
+	var entry P
+	goal := portKey[O, P]{toNode.id, toPort}
+
+	return yen(hop[O, P]{fromNode, entry, fromPort, nil}, k, func(start hop[O, P], bannedNodes map[O]bool, bannedEdges map[hopEdge[O, P]]bool) ([]hop[O, P], []float64, error) {
+		return graph.dijkstra(start, goal, costs, bannedNodes, bannedEdges)
+	})
+}
+
+func (graph *Graph[O, P]) KShortestPathsRef(fromRef O, fromPort P, toRef O, toPort P, k int, costs *Costs[O, P]) ([][]*PathSegment[O, P], []float64, error) {
+	fromNode, ok := graph.nodes[fromRef]
+	if !ok {
+		return nil, nil, fmt.Errorf("graph: from ref %v not found and thus cannot find paths", fromRef)
+	}
+
+	toNode, ok := graph.nodes[toRef]
+	if !ok {
+		return nil, nil, fmt.Errorf("graph: to ref %v not found and thus cannot find paths", toRef)
+	}
+
+	return graph.KShortestPaths(fromNode, fromPort, toNode, toPort, k, costs)
+}
+
+// shortestHops searches the cheapest hops from start to a fixed goal, never
+// entering bannedNodes and never taking bannedEdges.
+type shortestHops[O, P comparable] func(start hop[O, P], bannedNodes map[O]bool, bannedEdges map[hopEdge[O, P]]bool) ([]hop[O, P], []float64, error)
+
+// yen implements Yen's algorithm on top of a shortest path search.
+func yen[O, P comparable](start hop[O, P], k int, shortest shortestHops[O, P]) ([][]*PathSegment[O, P], []float64, error) {
+	if k <= 0 {
+		return [][]*PathSegment[O, P]{}, []float64{}, nil
+	}
+
+	hops, cumulative, err := shortest(start, nil, nil)
+	if errors.Is(err, ErrNoPath) {
+		return [][]*PathSegment[O, P]{}, []float64{}, nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	found := []*rankedHops[O, P]{{hops, cumulative}}
+	candidates := []*rankedHops[O, P]{}
+
+	for len(found) < k {
+		last := found[len(found)-1]
+
+		for i := 0; i < len(last.hops)-1; i++ {
+			root := last.hops[:i+1]
+
+			bannedEdges := map[hopEdge[O, P]]bool{}
+			for _, path := range found {
+				if len(path.hops) > i+1 && equalHops(path.hops[:i+1], root) {
+					bannedEdges[newHopEdge(path.hops[i], path.hops[i+1])] = true
+				}
+			}
+
+			bannedNodes := map[O]bool{}
+			for _, h := range root {
+				bannedNodes[h.node.id] = true
+			}
+
+			spur, spurCumulative, err := shortest(root[i], bannedNodes, bannedEdges)
+			if errors.Is(err, ErrNoPath) {
+				continue
+			} else if err != nil {
+				return nil, nil, err
+			}
+
+			candidate := &rankedHops[O, P]{
+				append(append([]hop[O, P]{}, root[:i]...), spur...),
+				append([]float64{}, last.cumulative[:i]...),
+			}
+
+			for _, c := range spurCumulative {
+				candidate.cumulative = append(candidate.cumulative, last.cumulative[i]+c)
+			}
+
+			if !containsHops(found, candidate) && !containsHops(candidates, candidate) {
+				candidates = append(candidates, candidate)
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		best := 0
+		for i, candidate := range candidates {
+			if candidate.cost() < candidates[best].cost() {
+				best = i
+			}
+		}
+
+		found = append(found, candidates[best])
+		candidates = append(candidates[:best], candidates[best+1:]...)
+	}
+
+	paths := make([][]*PathSegment[O, P], len(found))
+	pathCosts := make([]float64, len(found))
+	for i, path := range found {
+		paths[i] = hopsToPath(path.hops)
+		pathCosts[i] = path.cost()
+	}
+
+	return paths, pathCosts, nil
+}
+
+// dijkstra searches the cheapest sequence of hops from start to the state in
+// which goal's node is left via goal's port. Every step follows an outer
+// connection and then an inner transition of the reached node. Like in Find, a
+// node is never entered twice. Nodes in bannedNodes are never entered and edges
+// in bannedEdges are never taken.
+func (graph *Graph[O, P]) dijkstra(start hop[O, P], goal portKey[O, P], costs *Costs[O, P], bannedNodes map[O]bool, bannedEdges map[hopEdge[O, P]]bool) ([]hop[O, P], []float64, error) {
+	if !graph.occupancy.passable(start.node.id, start.entry, start.exit, true) {
+		return nil, nil, ErrNoPath
+	}
+
+	relax := func(from hop[O, P], visit func(next hop[O, P], weight float64)) error {
+		return graph.relax(from, costs, bannedNodes, bannedEdges, visit)
+	}
+
+	hops, cumulative, err := cheapestHops(start, goal, relax)
+	if err != nil || !reentersNode(hops) {
+		return hops, cumulative, err
+	}
+
+	return looplessHops(start, goal, relax)
+}
+
+// relax calls visit with every hop that may follow from and the weight of
+// taking it. Nodes in bannedNodes, edges in bannedEdges and occupied ports are
+// skipped.
+func (graph *Graph[O, P]) relax(from hop[O, P], costs *Costs[O, P], bannedNodes map[O]bool, bannedEdges map[hopEdge[O, P]]bool, visit func(next hop[O, P], weight float64)) error {
+	for _, connection := range graph.outgoing[from.key()] {
+		if bannedNodes[connection.ToNode.id] {
+			continue
+		}
+
+		for _, port := range connection.ToNode.Next(connection.ToPort) {
+			next := hop[O, P]{connection.ToNode, connection.ToPort, port, connection}
+			if bannedEdges[newHopEdge(from, next)] || !graph.occupancy.passable(connection.ToNode.id, connection.ToPort, port, false) {
+				continue
+			}
+
+			weight := costs.connection(connection) + costs.inner(connection.ToNode, connection.ToPort, port)
+			if weight < 0 {
+				return fmt.Errorf("graph: negative cost %v for %s -> %v", weight, connection, port)
+			}
+
+			visit(next, weight)
+		}
+	}
+
+	return nil
+}
+
+// hopRelaxer calls visit with every hop that may follow from and the weight of
+// taking it.
+type hopRelaxer[O, P comparable] func(from hop[O, P], visit func(next hop[O, P], weight float64)) error
+
+// cheapestHops searches the cheapest hops from start to goal keeping a single
+// label per state. The hops found may enter a node more than once.
+func cheapestHops[O, P comparable](start hop[O, P], goal portKey[O, P], relax hopRelaxer[O, P]) ([]hop[O, P], []float64, error) {
+	startKey := start.key()
+	labels := map[portKey[O, P]]*dijkstraLabel[O, P]{startKey: {start, 0, nil, false, nil}}
+
+	queue := &dijkstraQueue[O, P]{}
+	heap.Push(queue, &dijkstraItem[O, P]{startKey, 0, 0, false, nil})
+	seq := 1
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(*dijkstraItem[O, P])
+
+		label := labels[item.key]
+		if label.done || item.cost > label.cost {
+			continue
+		}
+
+		label.done = true
+		if item.key == goal {
+			return label.unwind()
+		}
+
+		err := relax(label.hop, func(next hop[O, P], weight float64) {
+			cost := label.cost + weight
+			key := next.key()
+
+			if existing, ok := labels[key]; ok && (existing.done || existing.cost <= cost) {
+				return
+			}
+
+			labels[key] = &dijkstraLabel[O, P]{next, cost, label, false, nil}
+			heap.Push(queue, &dijkstraItem[O, P]{key, cost, seq, false, nil})
+			seq++
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return nil, nil, ErrNoPath
+}
+
+// looplessHops searches the cheapest hops from start to goal that never enter a
+// node twice. Every label keeps the nodes passed so far. A label is only dropped
+// for one at the same state that is at most as expensive and passes a subset of
+// its nodes, as any continuation of the former is then open to the latter, too.
+//
+// The number of labels may grow exponentially with the number of alternative
+// routes, so the cheaper cheapestHops is tried first: whenever its hops do not
+// enter a node twice, they are the answer.
+func looplessHops[O, P comparable](start hop[O, P], goal portKey[O, P], relax hopRelaxer[O, P]) ([]hop[O, P], []float64, error) {
+	index := nodeIndex[O]{}
+
+	first := &dijkstraLabel[O, P]{start, 0, nil, false, bitset{}.with(index.of(start.node.id))}
+	labels := map[portKey[O, P]][]*dijkstraLabel[O, P]{start.key(): {first}}
+
+	queue := &dijkstraQueue[O, P]{}
+	heap.Push(queue, &dijkstraItem[O, P]{start.key(), 0, 0, false, first})
+	seq := 1
+
+	for queue.Len() > 0 {
+		label := heap.Pop(queue).(*dijkstraItem[O, P]).label
+		if label.done {
+			continue
+		}
+
+		label.done = true
+		if label.hop.key() == goal {
+			return label.unwind()
+		}
+
+		err := relax(label.hop, func(next hop[O, P], weight float64) {
+			node := index.of(next.node.id)
+			if label.visited.has(node) {
+				return
+			}
+
+			successor := &dijkstraLabel[O, P]{next, label.cost + weight, label, false, label.visited.with(node)}
+			key := next.key()
+
+			var added bool
+			if labels[key], added = addLabel(labels[key], successor); added {
+				heap.Push(queue, &dijkstraItem[O, P]{key, successor.cost, seq, false, successor})
+				seq++
+			}
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return nil, nil, ErrNoPath
+}
+
+// addLabel adds label to the labels of a state unless one of them dominates it.
+// Labels dominated by label in turn are dropped and marked as done.
+func addLabel[O, P comparable](labels []*dijkstraLabel[O, P], label *dijkstraLabel[O, P]) ([]*dijkstraLabel[O, P], bool) {
+	for _, l := range labels {
+		if l.dominates(label) {
+			return labels, false
+		}
+	}
+
+	kept := labels[:0]
+	for _, l := range labels {
+		if label.dominates(l) {
+			l.done = true
+		} else {
+			kept = append(kept, l)
+		}
+	}
+
+	return append(kept, label), true
+}
+
+// reentersNode reports whether a node is passed more than once.
+func reentersNode[O, P comparable](hops []hop[O, P]) bool {
+	passed := map[O]bool{}
+	for _, h := range hops {
+		if passed[h.node.id] {
+			return true
+		}
+
+		passed[h.node.id] = true
+	}
+
+	return false
+}
+
+// nodeIndex interns node IDs as positions in a bitset.
+type nodeIndex[O comparable] map[O]int32
+
+func (index nodeIndex[O]) of(id O) int32 {
+	i, ok := index[id]
+	if !ok {
+		i = int32(len(index))
+		index[id] = i
+	}
+
+	return i
+}
+
+// hop is a node being passed from its entry to its exit port. The node has
+// been entered by the outer connection via.
+type hop[O, P comparable] struct {
+	node  *Node[O, P]
+	entry P
+	exit  P
+	via   *Connection[O, P]
+}
+
+func (h hop[O, P]) key() portKey[O, P] {
+	return portKey[O, P]{h.node.id, h.exit}
+}
+
+func (h hop[O, P]) equals(o hop[O, P]) bool {
+	return h.node.Equals(o.node) && h.entry == o.entry && h.exit == o.exit
+}
+
+type hopEdge[O, P comparable] struct {
+	from  portKey[O, P]
+	node  O
+	entry P
+	exit  P
+}
+
+func newHopEdge[O, P comparable](from, to hop[O, P]) hopEdge[O, P] {
+	return hopEdge[O, P]{from.key(), to.node.id, to.entry, to.exit}
+}
+
+func hopsToPath[O, P comparable](hops []hop[O, P]) []*PathSegment[O, P] {
+	path := make([]*PathSegment[O, P], len(hops))
+	for i, h := range hops {
+		path[i] = &PathSegment[O, P]{optional.Some(h.entry), h.node, optional.Some(h.exit), h.via}
+	}
+
+	return path
+}
+
+func equalHops[O, P comparable](a, b []hop[O, P]) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].equals(b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+type rankedHops[O, P comparable] struct {
+	hops       []hop[O, P]
+	cumulative []float64
+}
+
+func (ranked *rankedHops[O, P]) cost() float64 {
+	return ranked.cumulative[len(ranked.cumulative)-1]
+}
+
+func containsHops[O, P comparable](paths []*rankedHops[O, P], path *rankedHops[O, P]) bool {
+	for _, p := range paths {
+		if equalHops(p.hops, path.hops) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type dijkstraLabel[O, P comparable] struct {
+	hop      hop[O, P]
+	cost     float64
+	previous *dijkstraLabel[O, P]
+	done     bool
+	visited  bitset // Nodes passed, only kept by looplessHops
+}
+
+// dominates reports whether label is at most as expensive as other and passes
+// no node other does not pass.
+func (label *dijkstraLabel[O, P]) dominates(other *dijkstraLabel[O, P]) bool {
+	return label.cost <= other.cost && label.visited.subsetOf(other.visited)
+}
+
+func (label *dijkstraLabel[O, P]) unwind() ([]hop[O, P], []float64, error) {
+	hops := []hop[O, P]{}
+	cumulative := []float64{}
+
+	for l := label; l != nil; l = l.previous {
+		hops = append(hops, l.hop)
+		cumulative = append(cumulative, l.cost)
+	}
+
+	slices.Reverse(hops)
+	slices.Reverse(cumulative)
+
+	return hops, cumulative, nil
+}
+
+type dijkstraItem[O, P comparable] struct {
+	key     portKey[O, P]
+	cost    float64
+	seq     int
+	arrival bool // Whether key is the port a node is entered by
+	label   *dijkstraLabel[O, P]
+}
+
+type dijkstraQueue[O, P comparable] []*dijkstraItem[O, P]
+
+func (queue dijkstraQueue[O, P]) Len() int {
+	return len(queue)
+}
+
+func (queue dijkstraQueue[O, P]) Less(i, j int) bool {
+	if queue[i].cost != queue[j].cost {
+		return queue[i].cost < queue[j].cost
+	}
+
+	return queue[i].seq < queue[j].seq
+}
+
+func (queue dijkstraQueue[O, P]) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+}
+
+func (queue *dijkstraQueue[O, P]) Push(x any) {
+	*queue = append(*queue, x.(*dijkstraItem[O, P]))
+}
+
+func (queue *dijkstraQueue[O, P]) Pop() any {
+	old := *queue
+	item := old[len(old)-1]
+	*queue = old[:len(old)-1]
+	return item
+}
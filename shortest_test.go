@@ -0,0 +1,279 @@
+package graphs_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+func TestShortestPath(t *testing.T) {
+	graph := MakeGraph()
+
+	path, cost, err := graph.ShortestPathRef(1, "b", 6, "b", nil)
+	if err != nil {
+		t.Fatalf("error when finding shortest path: %s", err)
+	}
+
+	if cost != 4 {
+		t.Fatalf("expected cost 4, but got %v", cost)
+	}
+
+	if len(path) != 5 || path[0].Middle.Id() != 1 || path[4].Middle.Id() != 6 {
+		t.Fatalf("expected path from 1 to 6 with 5 segments, but got %v", path)
+	}
+
+	if path[4].Left.Unwrap() != "a" || path[4].Right.Unwrap() != "b" {
+		t.Fatalf("expected last segment to enter at 'a' and exit at 'b', but got %v", path[4])
+	}
+}
+
+func TestShortestPathCosts(t *testing.T) {
+	graph := MakeGraph()
+
+	costs := &graphs.Costs[int, string]{
+		Connection: func(connection *graphs.Connection[int, string]) float64 {
+			if connection.ToNode.Id() == 3 {
+				return 10
+			}
+			return 1
+		},
+		Inner: func(node *graphs.Node[int, string], from, to string) float64 {
+			return 0.5
+		},
+	}
+
+	path, cost, err := graph.ShortestPathRef(1, "b", 6, "b", costs)
+	if err != nil {
+		t.Fatalf("error when finding shortest path: %s", err)
+	}
+
+	if cost != 6 {
+		t.Fatalf("expected cost 6, but got %v", cost)
+	}
+
+	if path[2].Middle.Id() != 4 {
+		t.Fatalf("expected path to avoid 3, but got %v", path)
+	}
+}
+
+func TestShortestPathNotFound(t *testing.T) {
+	graph := MakeGraph()
+
+	if _, _, err := graph.ShortestPathRef(1, "a", 6, "b", nil); !errors.Is(err, graphs.ErrNoPath) {
+		t.Fatalf("expected ErrNoPath, but got %v", err)
+	}
+}
+
+func TestKShortestPaths(t *testing.T) {
+	graph := MakeGraph()
+
+	costs := &graphs.Costs[int, string]{
+		Connection: func(connection *graphs.Connection[int, string]) float64 {
+			if connection.ToNode.Id() == 3 {
+				return 10
+			}
+			return 1
+		},
+	}
+
+	paths, pathCosts, err := graph.KShortestPathsRef(1, "b", 6, "b", 5, costs)
+	if err != nil {
+		t.Fatalf("error when finding k shortest paths: %s", err)
+	}
+
+	if len(paths) != 2 || len(pathCosts) != 2 {
+		t.Fatalf("expected 2 paths, but got %d: %v", len(paths), paths)
+	}
+
+	if pathCosts[0] != 4 || pathCosts[1] != 13 {
+		t.Fatalf("expected costs [4 13], but got %v", pathCosts)
+	}
+
+	if paths[0][2].Middle.Id() != 4 || paths[1][2].Middle.Id() != 3 {
+		t.Fatalf("expected paths via 4 and then via 3, but got %v", paths)
+	}
+
+	if paths[1][1].Left.Unwrap() != "c" || paths[1][1].Right.Unwrap() != "d" {
+		t.Fatalf("expected second path to pass 2 from 'c' to 'd', but got %v", paths[1][1])
+	}
+}
+
+func TestKShortestPathsLimit(t *testing.T) {
+	graph := MakeGraph()
+
+	paths, _, err := graph.KShortestPathsRef(1, "b", 6, "b", 1, nil)
+	if err != nil {
+		t.Fatalf("error when finding k shortest paths: %s", err)
+	}
+
+	if len(paths) != 1 {
+		t.Fatalf("expected 1 path, but got %d: %v", len(paths), paths)
+	}
+}
+
+/*
+   X can be passed directly from x1 to x4 at a high cost or by leaving it to Y
+   and coming back, which is cheaper but enters X twice.
+
+   A -out-x1- X -x4-t1- T
+          x2 /  \ x3
+            y1-Y-y2
+*/
+
+func MakeLoopGraph() *graphs.Graph[string, string] {
+	a := graphs.NewNode[string, string]("A")
+	x := graphs.NewNode[string, string]("X")
+	y := graphs.NewNode[string, string]("Y")
+	target := graphs.NewNode[string, string]("T")
+
+	a.Connect("in", "out")
+	x.ConnectWith("x1", "x4", graphs.Transition{Weight: 10})
+	x.Connect("x1", "x2")
+	x.Connect("x3", "x4")
+	y.Connect("y1", "y2")
+	target.Connect("t1", "t2")
+
+	graph := graphs.NewGraph[string, string]()
+	graph.AddNode(a)
+	graph.AddNode(x)
+	graph.AddNode(y)
+	graph.AddNode(target)
+
+	graph.ConnectRef("A", "out", "X", "x1")
+	graph.ConnectRef("X", "x2", "Y", "y1")
+	graph.ConnectRef("Y", "y2", "X", "x3")
+	graph.ConnectRef("X", "x4", "T", "t1")
+
+	return graph
+}
+
+func TestShortestPathNoReentry(t *testing.T) {
+	graph := MakeLoopGraph()
+
+	for name, shortest := range map[string]func(fromRef, fromPort, toRef, toPort string, costs *graphs.Costs[string, string]) ([]*graphs.PathSegment[string, string], float64, error){
+		"graph":    graph.ShortestPathRef,
+		"compiled": graph.Compile().ShortestPathRef,
+	} {
+		path, cost, err := shortest("A", "out", "T", "t2", nil)
+		if err != nil {
+			t.Fatalf("%s: error when finding shortest path: %s", name, err)
+		}
+
+		if len(path) != 3 || cost != 12 {
+			t.Fatalf("%s: expected direct path via X with cost 12, but got %v with cost %v", name, path, cost)
+		}
+	}
+
+	paths, _, err := graph.KShortestPathsRef("A", "out", "T", "t2", 3, nil)
+	if err != nil {
+		t.Fatalf("error when finding k shortest paths: %s", err)
+	}
+
+	found, err := graph.FindRef("A", "out", "T", "t2")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 1 || len(found) != 1 {
+		t.Fatalf("expected a single loopless path, but got %v and %v from Find", paths, found)
+	}
+}
+
+/*
+   A is left either towards N or towards B, both of which lead on to X. From X,
+   N is entered at c and left at d towards G. Reaching X through N is as cheap
+   as through B, but only the path through B reaches G without entering N twice.
+
+   S -s-in- A -out1-a- N -b-p- X -q-c- N -d-g- G
+              -out2-a- B -b-p-
+*/
+
+func MakeReentryGraph() *graphs.Graph[string, string] {
+	s := graphs.NewNode[string, string]("S")
+	a := graphs.NewNode[string, string]("A")
+	n := graphs.NewNode[string, string]("N")
+	b := graphs.NewNode[string, string]("B")
+	x := graphs.NewNode[string, string]("X")
+	g := graphs.NewNode[string, string]("G")
+
+	a.Connect("in", "out1")
+	a.Connect("in", "out2")
+	n.Connect("a", "b")
+	n.Connect("c", "d")
+	b.Connect("a", "b")
+	x.Connect("p", "q")
+	g.Connect("g", "h")
+
+	graph := graphs.NewGraph[string, string]()
+	graph.AddNode(s)
+	graph.AddNode(a)
+	graph.AddNode(n)
+	graph.AddNode(b)
+	graph.AddNode(x)
+	graph.AddNode(g)
+
+	graph.ConnectRef("S", "s", "A", "in")
+	graph.ConnectRef("A", "out1", "N", "a")
+	graph.ConnectRef("N", "b", "X", "p")
+	graph.ConnectRef("A", "out2", "B", "a")
+	graph.ConnectRef("B", "b", "X", "p")
+	graph.ConnectRef("X", "q", "N", "c")
+	graph.ConnectRef("N", "d", "G", "g")
+
+	return graph
+}
+
+func TestShortestPathAvoidsReentry(t *testing.T) {
+	graph := MakeReentryGraph()
+
+	found, err := graph.FindRef("S", "s", "G", "h")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(found) != 1 || pathIds(found[0]) != "SABXNG" {
+		t.Fatalf("expected a single path S-A-B-X-N-G from Find, but got %v", found)
+	}
+
+	for name, shortest := range map[string]func(fromRef, fromPort, toRef, toPort string, costs *graphs.Costs[string, string]) ([]*graphs.PathSegment[string, string], float64, error){
+		"graph":    graph.ShortestPathRef,
+		"compiled": graph.Compile().ShortestPathRef,
+	} {
+		path, cost, err := shortest("S", "s", "G", "h", nil)
+		if err != nil {
+			t.Fatalf("%s: error when finding shortest path: %s", name, err)
+		}
+
+		if pathIds(path) != "SABXNG" || cost != 5 {
+			t.Fatalf("%s: expected path S-A-B-X-N-G with cost 5, but got %v with cost %v", name, path, cost)
+		}
+	}
+}
+
+func pathIds(path []*graphs.PathSegment[string, string]) string {
+	ids := ""
+	for _, segment := range path {
+		ids += segment.Middle.Id()
+	}
+
+	return ids
+}
+
+func TestKShortestPathsAvoidsReentry(t *testing.T) {
+	graph := MakeReentryGraph()
+
+	for name, kShortest := range map[string]func(fromRef, fromPort, toRef, toPort string, k int, costs *graphs.Costs[string, string]) ([][]*graphs.PathSegment[string, string], []float64, error){
+		"graph":    graph.KShortestPathsRef,
+		"compiled": graph.Compile().KShortestPathsRef,
+	} {
+		paths, pathCosts, err := kShortest("S", "s", "G", "h", 3, nil)
+		if err != nil {
+			t.Fatalf("%s: error when finding k shortest paths: %s", name, err)
+		}
+
+		if len(paths) != 1 || pathIds(paths[0]) != "SABXNG" || pathCosts[0] != 5 {
+			t.Fatalf("%s: expected the single path S-A-B-X-N-G with cost 5, but got %v with costs %v", name, paths, pathCosts)
+		}
+	}
+}
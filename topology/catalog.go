@@ -0,0 +1,142 @@
+package topology
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/yannickkirschen/graphs/inventory"
+)
+
+type RouteKey[O comparable] struct {
+	From O
+	To   O
+}
+
+// RouteCatalog holds all routes of a topology indexed by their start and end
+// objects.
+type RouteCatalog[O, C, P comparable] struct {
+	routes []*Route[O, C, P]
+	byKey  map[RouteKey[O]][]*Route[O, C, P]
+	byId   map[string]*Route[O, C, P]
+}
+
+// RouteCatalog computes all routes between objects that allow paths to start
+// and objects that allow paths to end, see Routes.
+func (top *Topology[O, C, P]) RouteCatalog() (*RouteCatalog[O, C, P], error) {
+	routes, err := top.Routes()
+	if err != nil {
+		return nil, err
+	}
+
+	return newRouteCatalog(routes), nil
+}
+
+func newRouteCatalog[O, C, P comparable](routes []*Route[O, C, P]) *RouteCatalog[O, C, P] {
+	catalog := &RouteCatalog[O, C, P]{
+		routes,
+		map[RouteKey[O]][]*Route[O, C, P]{},
+		map[string]*Route[O, C, P]{},
+	}
+
+	for _, route := range routes {
+		key := RouteKey[O]{route.From.Id, route.To.Id}
+		catalog.byKey[key] = append(catalog.byKey[key], route)
+		catalog.byId[route.Id] = route
+	}
+
+	return catalog
+}
+
+func (catalog *RouteCatalog[O, C, P]) Routes() []*Route[O, C, P] {
+	return catalog.routes
+}
+
+// Get returns all routes from one object to another.
+func (catalog *RouteCatalog[O, C, P]) Get(from, to O) []*Route[O, C, P] {
+	return catalog.byKey[RouteKey[O]{from, to}]
+}
+
+func (catalog *RouteCatalog[O, C, P]) Route(id string) (*Route[O, C, P], bool) {
+	route, ok := catalog.byId[id]
+	return route, ok
+}
+
+// Keys lists all pairs of objects that are connected by at least one route in
+// the order of the catalog.
+func (catalog *RouteCatalog[O, C, P]) Keys() []RouteKey[O] {
+	keys := []RouteKey[O]{}
+	for _, route := range catalog.routes {
+		key := RouteKey[O]{route.From.Id, route.To.Id}
+		if len(keys) == 0 || keys[len(keys)-1] != key {
+			keys = append(keys, key)
+		}
+	}
+
+	return keys
+}
+
+type CatalogModel[O, P comparable] struct {
+	Routes []*RouteModel[O, P] `yaml:"routes"`
+}
+
+type RouteModel[O, P comparable] struct {
+	Id    string             `yaml:"id"`
+	From  O                  `yaml:"from"`
+	To    O                  `yaml:"to"`
+	Steps []*StepModel[O, P] `yaml:"steps"`
+}
+
+type StepModel[O, P comparable] struct {
+	Object O      `yaml:"object"`
+	Entry  *P     `yaml:"entry,omitempty"`
+	Exit   *P     `yaml:"exit,omitempty"`
+	State  string `yaml:"state,omitempty"`
+}
+
+func (catalog *RouteCatalog[O, C, P]) ToModel() *CatalogModel[O, P] {
+	model := &CatalogModel[O, P]{[]*RouteModel[O, P]{}}
+
+	for _, route := range catalog.routes {
+		routeModel := &RouteModel[O, P]{route.Id, route.From.Id, route.To.Id, []*StepModel[O, P]{}}
+
+		for i, segment := range route.Path {
+			step := &StepModel[O, P]{Object: segment.Middle.Id()}
+
+			// A route starts by leaving its first object, so it has no entry.
+			if i > 0 && segment.Left.IsSome() {
+				entry := segment.Left.Unwrap()
+				step.Entry = &entry
+			}
+
+			if segment.Right.IsSome() {
+				exit := segment.Right.Unwrap()
+				step.Exit = &exit
+			}
+
+			if transition, ok := segment.Transition(); ok {
+				step.State = transition.State
+			}
+
+			routeModel.Steps = append(routeModel.Steps, step)
+		}
+
+		model.Routes = append(model.Routes, routeModel)
+	}
+
+	return model
+}
+
+// Marshal serializes the catalog to YAML. Routes keep the order of the catalog,
+// so catalogs of different versions of a topology can be diffed.
+func (catalog *RouteCatalog[O, C, P]) Marshal() ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := catalog.Write(&buffer); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (catalog *RouteCatalog[O, C, P]) Write(w io.Writer) error {
+	return inventory.Encode(w, inventory.FormatYAML, catalog.ToModel())
+}
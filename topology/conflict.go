@@ -0,0 +1,121 @@
+package topology
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+// Conflict is a set of reasons why two routes cannot be used at the same time.
+type Conflict int
+
+const (
+	SharedObject      Conflict = 1 << iota // Both routes pass the same object
+	IncompatibleState                      // Both routes require the same object to be in different states
+)
+
+func (conflict Conflict) Has(reason Conflict) bool {
+	return conflict&reason != 0
+}
+
+func (conflict Conflict) String() string {
+	reasons := []string{}
+	if conflict.Has(SharedObject) {
+		reasons = append(reasons, "object")
+	}
+
+	if conflict.Has(IncompatibleState) {
+		reasons = append(reasons, "state")
+	}
+
+	return strings.Join(reasons, "+")
+}
+
+// ConflictMatrix tells for each pair of routes whether they conflict.
+// Conflicts[i][j] is the conflict between Routes[i] and Routes[j].
+type ConflictMatrix[O, C, P comparable] struct {
+	Routes    []*Route[O, C, P]
+	Conflicts [][]Conflict
+}
+
+// ConflictMatrix computes the conflicts between all routes of the topology, as
+// found by Routes. A route does not conflict with itself.
+func (top *Topology[O, C, P]) ConflictMatrix() (*ConflictMatrix[O, C, P], error) {
+	routes, err := top.Routes()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewConflictMatrix(routes), nil
+}
+
+func NewConflictMatrix[O, C, P comparable](routes []*Route[O, C, P]) *ConflictMatrix[O, C, P] {
+	states := make([]map[O]string, len(routes))
+	for i, route := range routes {
+		states[i] = map[O]string{}
+		for _, segment := range route.Path {
+			states[i][segment.Middle.Id()] = ""
+		}
+
+		for _, state := range graphs.RequiredStates(route.Path) {
+			states[i][state.Node] = state.State
+		}
+	}
+
+	conflicts := make([][]Conflict, len(routes))
+	for i := range routes {
+		conflicts[i] = make([]Conflict, len(routes))
+	}
+
+	for i := range routes {
+		for j := i + 1; j < len(routes); j++ {
+			var conflict Conflict
+			for node, state := range states[i] {
+				other, ok := states[j][node]
+				if !ok {
+					continue
+				}
+
+				conflict |= SharedObject
+				if state != "" && other != "" && state != other {
+					conflict |= IncompatibleState
+				}
+			}
+
+			conflicts[i][j] = conflict
+			conflicts[j][i] = conflict
+		}
+	}
+
+	return &ConflictMatrix[O, C, P]{routes, conflicts}
+}
+
+// WriteCSV writes the matrix with route IDs as row and column headers.
+func (matrix *ConflictMatrix[O, C, P]) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{""}
+	for _, route := range matrix.Routes {
+		header = append(header, route.Id)
+	}
+
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for i, route := range matrix.Routes {
+		row := []string{route.Id}
+		for _, conflict := range matrix.Conflicts[i] {
+			row = append(row, conflict.String())
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
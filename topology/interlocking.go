@@ -0,0 +1,120 @@
+package topology
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/moznion/go-optional"
+)
+
+var interlockingHeader = []string{"Route", "From", "To", "Step", "Object", "Label", "Class", "Entry", "Exit", "State"}
+
+// WriteInterlockingCSV writes a route setting table listing every object each
+// route passes with the ports it is entered and left by.
+func (top *Topology[O, C, P]) WriteInterlockingCSV(w io.Writer) error {
+	routes, err := top.Routes()
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(interlockingHeader); err != nil {
+		return err
+	}
+
+	for _, route := range routes {
+		if err := writer.WriteAll(top.interlockingRows(route)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WriteInterlockingMarkdown writes the same table as WriteInterlockingCSV as a
+// Markdown section per route.
+func (top *Topology[O, C, P]) WriteInterlockingMarkdown(w io.Writer) error {
+	routes, err := top.Routes()
+	if err != nil {
+		return err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("# Interlocking table\n")
+
+	for _, route := range routes {
+		fmt.Fprintf(&builder, "\n## Route %s: %s → %s\n\n", markdownEscape(route.Id), markdownEscape(route.From.Label), markdownEscape(route.To.Label))
+		writeMarkdownRow(&builder, interlockingHeader[3:])
+		writeMarkdownRow(&builder, []string{"---:", "---", "---", "---", "---", "---", "---"})
+
+		for _, row := range top.interlockingRows(route) {
+			writeMarkdownRow(&builder, row[3:])
+		}
+	}
+
+	_, err = io.WriteString(w, builder.String())
+	return err
+}
+
+func (top *Topology[O, C, P]) interlockingRows(route *Route[O, C, P]) [][]string {
+	rows := [][]string{}
+
+	for i, segment := range route.Path {
+		var label, class string
+		if object, err := top.inv.GetObject(segment.Middle.Id()).Take(); err == nil {
+			label = object.Label
+			class = object.Class.Label
+		}
+
+		// A route starts by leaving its first object, so it has no entry.
+		entry := formatPort(segment.Left)
+		if i == 0 {
+			entry = ""
+		}
+
+		var state string
+		if transition, ok := segment.Transition(); ok {
+			state = transition.State
+		}
+
+		rows = append(rows, []string{
+			route.Id,
+			fmt.Sprint(route.From.Id),
+			fmt.Sprint(route.To.Id),
+			fmt.Sprint(i + 1),
+			fmt.Sprint(segment.Middle.Id()),
+			label,
+			class,
+			entry,
+			formatPort(segment.Right),
+			state,
+		})
+	}
+
+	return rows
+}
+
+func formatPort[P comparable](port optional.Option[P]) string {
+	if port.IsNone() {
+		return ""
+	}
+
+	return fmt.Sprint(port.Unwrap())
+}
+
+func writeMarkdownRow(builder *strings.Builder, cells []string) {
+	builder.WriteString("|")
+	for _, cell := range cells {
+		builder.WriteString(" ")
+		builder.WriteString(markdownEscape(cell))
+		builder.WriteString(" |")
+	}
+	builder.WriteString("\n")
+}
+
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
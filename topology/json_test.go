@@ -0,0 +1,78 @@
+package topology_test
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+	"github.com/yannickkirschen/graphs/inventory"
+	"github.com/yannickkirschen/graphs/topology"
+)
+
+type PointSpec struct {
+	Speed int    `yaml:"speed"`
+	Motor string `yaml:"motor"`
+}
+
+func TestParseFileJSON(t *testing.T) {
+	inv, err := inventory.ParseFileWithSpec[string, string, string]("testdata/inventory.json", inventory.SpecMap{"Point": reflect.TypeOf(PointSpec{})})
+	if err != nil {
+		t.Fatalf("error when parsing inventory: %s", err)
+	}
+
+	spec, err := inv.GetObject("W1").Unwrap().Spec.Take()
+	if err != nil || *spec.(*PointSpec) != (PointSpec{40, "M1"}) {
+		t.Fatalf("expected spec of W1 to be parsed, but got %v", spec)
+	}
+
+	data, err := inv.Marshal()
+	if err != nil {
+		t.Fatalf("error when marshalling inventory: %s", err)
+	}
+
+	if !strings.Contains(string(data), "    spec:\n      speed: 40\n      motor: M1\n") {
+		t.Fatalf("expected spec of W1 to be marshalled, but got:\n%s", data)
+	}
+
+	top, err := topology.ParseFile(inv, "testdata/topology.json")
+	if err != nil {
+		t.Fatalf("error when parsing topology: %s", err)
+	}
+
+	routes, err := top.Routes()
+	if err != nil {
+		t.Fatalf("error when finding routes: %s", err)
+	}
+
+	expected, err := MakeTopology(t).Routes()
+	if err != nil {
+		t.Fatalf("error when finding routes: %s", err)
+	}
+
+	if len(routes) != len(expected) {
+		t.Fatalf("expected %d routes as in YAML, but got %d", len(expected), len(routes))
+	}
+
+	connection, _ := top.Graph().FindConnection(top.Graph().Node("S1").Unwrap(), "b")
+	if length, ok := graphs.Attribute[int](connection.Attributes, "length"); !ok || length != 120 {
+		t.Fatalf("expected length 120 as in YAML, but got %v (%T)", connection.Attributes, connection.Attributes["length"])
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	top, err := topology.ParseJSON(MakeTopology(t).Inventory(), io.NopCloser(strings.NewReader(`{"connections": [{"from": "S1", "fromPort": "b", "to": "W1", "toPort": "head", "attributes": {"length": 120, "gradient": 1.5}}]}`)))
+	if err != nil {
+		t.Fatalf("error when parsing topology JSON: %s", err)
+	}
+
+	connection, ok := top.Graph().FindConnection(top.Graph().Node("S1").Unwrap(), "b")
+	if !ok {
+		t.Fatalf("expected connection from S1")
+	}
+
+	if gradient, ok := graphs.Attribute[float64](connection.Attributes, "gradient"); !ok || gradient != 1.5 {
+		t.Fatalf("expected gradient 1.5, but got %v", connection.Attributes)
+	}
+}
@@ -0,0 +1,71 @@
+package topology
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+
+	"github.com/yannickkirschen/graphs"
+	"github.com/yannickkirschen/graphs/inventory"
+)
+
+// ToModel converts the connections of the topology's graph back to a model.
+// Connections keep the order in which they have been connected. A connection
+// and its reverse with equal attributes are merged into a bidirectional one.
+func (top *Topology[O, C, P]) ToModel() *Model[O, C, P] {
+	model := &Model[O, C, P]{[]*Connection[O, C, P]{}}
+	merged := map[*graphs.Connection[O, P]]bool{}
+
+	for connection := range top.graph.Connections() {
+		if merged[connection] {
+			continue
+		}
+
+		bidirectional := false
+		for _, reverse := range top.graph.FindConnections(connection.ToNode, connection.ToPort) {
+			if reverse != connection && !merged[reverse] && reverse.ToNode.Equals(connection.FromNode) && reverse.ToPort == connection.FromPort && reflect.DeepEqual(reverse.Attributes, connection.Attributes) {
+				merged[reverse] = true
+				bidirectional = true
+				break
+			}
+		}
+
+		merged[connection] = true
+		model.Connections = append(model.Connections, &Connection[O, C, P]{
+			connection.FromNode.Id(),
+			connection.FromPort,
+			connection.ToNode.Id(),
+			connection.ToPort,
+			bidirectional,
+			connection.Attributes,
+		})
+	}
+
+	return model
+}
+
+// Marshal serializes the connections of the topology to YAML. The inventory has
+// to be serialized on its own.
+func (top *Topology[O, C, P]) Marshal() ([]byte, error) {
+	return top.marshal(inventory.FormatYAML)
+}
+
+// WriteFile writes the connections of the topology to a file, as JSON if its
+// name ends with .json and as YAML otherwise.
+func (top *Topology[O, C, P]) WriteFile(filename string) error {
+	data, err := top.marshal(inventory.DetectFormat(filename, nil))
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, data, 0o644)
+}
+
+func (top *Topology[O, C, P]) marshal(format inventory.Format) ([]byte, error) {
+	var buffer bytes.Buffer
+	if err := inventory.Encode(&buffer, format, top.ToModel()); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,68 @@
+package topology_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/yannickkirschen/graphs/inventory"
+	"github.com/yannickkirschen/graphs/topology"
+)
+
+func TestMarshalRoundTrip(t *testing.T) {
+	top := MakeTopology(t)
+
+	for _, test := range []struct {
+		filename string
+		marshal  func() ([]byte, error)
+	}{
+		{"testdata/inventory.yaml", top.Inventory().Marshal},
+		{"testdata/topology.yaml", top.Marshal},
+	} {
+		expected, err := os.ReadFile(test.filename)
+		if err != nil {
+			t.Fatalf("error when reading %s: %s", test.filename, err)
+		}
+
+		data, err := test.marshal()
+		if err != nil {
+			t.Fatalf("error when marshalling %s: %s", test.filename, err)
+		}
+
+		if string(data) != string(expected) {
+			t.Fatalf("expected output to equal %s, but got:\n%s", test.filename, data)
+		}
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	top := MakeTopology(t)
+	dir := t.TempDir()
+
+	if err := top.Inventory().WriteFile(filepath.Join(dir, "inventory.json")); err != nil {
+		t.Fatalf("error when writing inventory: %s", err)
+	}
+
+	if err := top.WriteFile(filepath.Join(dir, "topology.json")); err != nil {
+		t.Fatalf("error when writing topology: %s", err)
+	}
+
+	inv, err := inventory.ParseFile[string, string, string](filepath.Join(dir, "inventory.json"))
+	if err != nil {
+		t.Fatalf("error when parsing written inventory: %s", err)
+	}
+
+	written, err := topology.ParseFile(inv, filepath.Join(dir, "topology.json"))
+	if err != nil {
+		t.Fatalf("error when parsing written topology: %s", err)
+	}
+
+	routes, err := written.Routes()
+	if err != nil {
+		t.Fatalf("error when finding routes: %s", err)
+	}
+
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes in written topology, but got %d", len(routes))
+	}
+}
@@ -0,0 +1,73 @@
+package topology
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+// MermaidSubgraphs tells how WriteMermaid groups objects.
+type MermaidSubgraphs int
+
+const (
+	NoSubgraphs     MermaidSubgraphs = iota
+	ClassSubgraphs                   // One subgraph per class holding its objects
+	ObjectSubgraphs                  // One subgraph per object holding its ports
+)
+
+type MermaidOptions struct {
+	Direction string // Flowchart direction, LR if empty
+	Subgraphs MermaidSubgraphs
+}
+
+// WriteMermaid writes the topology as a Mermaid flowchart. Objects and ports
+// are labelled with the labels from the inventory. Options may be nil.
+func (top *Topology[O, C, P]) WriteMermaid(w io.Writer, options *MermaidOptions) error {
+	if options == nil {
+		options = &MermaidOptions{}
+	}
+
+	graphOptions := &graphs.MermaidOptions[O, P]{
+		Direction: options.Direction,
+		Label: func(node *graphs.Node[O, P]) string {
+			object, err := top.inv.GetObject(node.Id()).Take()
+			if err != nil || object.Label == "" {
+				return fmt.Sprint(node.Id())
+			}
+
+			return object.Label
+		},
+		PortLabel: func(node *graphs.Node[O, P], port P) string {
+			object, err := top.inv.GetObject(node.Id()).Take()
+			if err != nil || object.Class.Ports[port] == nil || object.Class.Ports[port].Label == "" {
+				return fmt.Sprint(port)
+			}
+
+			return object.Class.Ports[port].Label
+		},
+		Ports: options.Subgraphs == ObjectSubgraphs,
+	}
+
+	if options.Subgraphs == ClassSubgraphs {
+		graphOptions.Group = func(node *graphs.Node[O, P]) string {
+			object, err := top.inv.GetObject(node.Id()).Take()
+			if err != nil {
+				return ""
+			}
+
+			return fmt.Sprint(object.Class.Id)
+		}
+
+		graphOptions.GroupLabel = func(node *graphs.Node[O, P]) string {
+			object, err := top.inv.GetObject(node.Id()).Take()
+			if err != nil || object.Class.Label == "" {
+				return graphOptions.Group(node)
+			}
+
+			return object.Class.Label
+		}
+	}
+
+	return top.graph.WriteMermaid(w, graphOptions)
+}
@@ -1,6 +1,8 @@
 package topology
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
@@ -11,15 +13,49 @@ import (
 )
 
 type Model[O, C, P comparable] struct {
-	Connections []*Connection[O, C, P] `yaml:"connections"`
+	Connections []*Connection[O, C, P] `yaml:"connections" json:"connections"`
 }
 
 type Connection[O, C, P comparable] struct {
-	From          O    `yaml:"from"`
-	FromPort      P    `yaml:"fromPort"`
-	To            O    `yaml:"to"`
-	ToPort        P    `yaml:"toPort"`
-	Bidirectional bool `yaml:"bidirectional"`
+	From          O                 `yaml:"from" json:"from"`
+	FromPort      P                 `yaml:"fromPort" json:"fromPort"`
+	To            O                 `yaml:"to" json:"to"`
+	ToPort        P                 `yaml:"toPort" json:"toPort"`
+	Bidirectional bool              `yaml:"bidirectional,omitempty" json:"bidirectional,omitempty"`
+	Attributes    graphs.Attributes `yaml:"attributes,omitempty" json:"attributes,omitempty"`
+}
+
+type connectionJSON[O, P comparable] struct {
+	From          O               `json:"from"`
+	FromPort      P               `json:"fromPort"`
+	To            O               `json:"to"`
+	ToPort        P               `json:"toPort"`
+	Bidirectional bool            `json:"bidirectional,omitempty"`
+	Attributes    json.RawMessage `json:"attributes,omitempty"`
+}
+
+// UnmarshalJSON reads the attributes like YAML does, so that whole numbers are
+// ints rather than float64. This works as JSON is valid YAML.
+func (connection *Connection[O, C, P]) UnmarshalJSON(data []byte) error {
+	aux := &connectionJSON[O, P]{}
+	if err := json.Unmarshal(data, aux); err != nil {
+		return err
+	}
+
+	connection.From = aux.From
+	connection.FromPort = aux.FromPort
+	connection.To = aux.To
+	connection.ToPort = aux.ToPort
+	connection.Bidirectional = aux.Bidirectional
+	connection.Attributes = nil
+
+	if len(aux.Attributes) > 0 {
+		if err := yaml.Unmarshal(aux.Attributes, &connection.Attributes); err != nil {
+			return fmt.Errorf("cannot decode attributes of connection from %v to %v: %s", aux.From, aux.To, err)
+		}
+	}
+
+	return nil
 }
 
 func (model *Model[O, C, P]) ToGraph(inv *inventory.Inventory[O, C, P]) *graphs.Graph[O, P] {
@@ -30,18 +66,20 @@ func (model *Model[O, C, P]) ToGraph(inv *inventory.Inventory[O, C, P]) *graphs.
 
 	for _, connection := range model.Connections {
 		if connection.Bidirectional {
-			g.ConnectRefBi(
+			g.ConnectRefBiWith(
 				connection.From,
 				connection.FromPort,
 				connection.To,
 				connection.ToPort,
+				connection.Attributes,
 			)
 		} else {
-			g.ConnectRef(
+			g.ConnectRefWith(
 				connection.From,
 				connection.FromPort,
 				connection.To,
 				connection.ToPort,
+				connection.Attributes,
 			)
 		}
 	}
@@ -54,19 +92,28 @@ func (model *Model[O, C, P]) ToTopology(inv *inventory.Inventory[O, C, P]) *Topo
 }
 
 func Parse[O, C, P comparable](inv *inventory.Inventory[O, C, P], r io.ReadCloser) (*Topology[O, C, P], error) {
+	return parse(inv, r, inventory.FormatYAML)
+}
+
+func ParseJSON[O, C, P comparable](inv *inventory.Inventory[O, C, P], r io.ReadCloser) (*Topology[O, C, P], error) {
+	return parse(inv, r, inventory.FormatJSON)
+}
+
+func parse[O, C, P comparable](inv *inventory.Inventory[O, C, P], r io.Reader, format inventory.Format) (*Topology[O, C, P], error) {
 	var model *Model[O, C, P]
-	if err := yaml.NewDecoder(r).Decode(&model); err != nil {
+	if err := inventory.Decode(r, format, &model); err != nil {
 		return nil, fmt.Errorf("error parsing input: %s", err)
 	}
 
 	return model.ToTopology(inv), nil
 }
 
+// ParseFile parses a YAML or JSON file, see inventory.DetectFormat.
 func ParseFile[O, C, P comparable](inv *inventory.Inventory[O, C, P], filename string) (*Topology[O, C, P], error) {
-	f, err := os.Open(filename)
+	content, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, fmt.Errorf("error opening %s: %s", filename, err)
 	}
 
-	return Parse(inv, f)
+	return parse(inv, bytes.NewReader(content), inventory.DetectFormat(filename, content))
 }
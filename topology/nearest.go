@@ -0,0 +1,47 @@
+package topology
+
+import (
+	"github.com/yannickkirschen/graphs"
+	"github.com/yannickkirschen/graphs/inventory"
+)
+
+// NearestObject is an object found by Nearest along with the cheapest path to
+// it from the start object.
+type NearestObject[O, C, P comparable] struct {
+	Object *inventory.Object[O, C, P]
+	Path   []*graphs.PathSegment[O, P]
+	Hops   int
+	Cost   float64
+}
+
+// Nearest expands outward from the path construction start of an object and
+// returns the reachable objects for which match holds, nearest first. A limit
+// of 0 or less returns all of them. Costs may be nil to count connections.
+func (top *Topology[O, C, P]) Nearest(fromRef O, match func(object *inventory.Object[O, C, P]) bool, limit int, costs *graphs.Costs[O, P]) ([]*NearestObject[O, C, P], error) {
+	from, err := top.start(fromRef)
+	if err != nil {
+		return nil, err
+	}
+
+	reached, err := top.graph.NearestRef(from.Id, from.Class.PathConstruction.Start.Id, func(node *graphs.Node[O, P]) bool {
+		object, err := top.inv.GetObject(node.Id()).Take()
+		return err == nil && match(object)
+	}, limit, costs)
+	if err != nil {
+		return nil, err
+	}
+
+	nearest := make([]*NearestObject[O, C, P], len(reached))
+	for i, r := range reached {
+		nearest[i] = &NearestObject[O, C, P]{top.inv.GetObject(r.Node.Id()).Unwrap(), r.Path, r.Hops, r.Cost}
+	}
+
+	return nearest, nil
+}
+
+// NearestOfClass returns the reachable objects of a class, nearest first.
+func (top *Topology[O, C, P]) NearestOfClass(fromRef O, classId C, limit int, costs *graphs.Costs[O, P]) ([]*NearestObject[O, C, P], error) {
+	return top.Nearest(fromRef, func(object *inventory.Object[O, C, P]) bool {
+		return object.Class.Id == classId
+	}, limit, costs)
+}
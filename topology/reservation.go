@@ -0,0 +1,206 @@
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"sync"
+
+	"github.com/yannickkirschen/graphs"
+)
+
+var ErrReservationConflict = errors.New("reservation conflict")
+
+// OverlapMode decides when two paths are considered to overlap.
+type OverlapMode int
+
+const (
+	NodeExclusive OverlapMode = iota // Paths overlap if they pass the same object
+	PortExclusive                    // Paths overlap if they use the same port of an object
+)
+
+type ReservationId uint64
+
+type Reservation[O, P comparable] struct {
+	Id   ReservationId
+	Path []*graphs.PathSegment[O, P]
+
+	nodes map[O]bool
+	ports map[graphs.PortRef[O, P]]bool
+}
+
+func (reservation *Reservation[O, P]) From() O {
+	return reservation.Path[0].Middle.Id()
+}
+
+func (reservation *Reservation[O, P]) To() O {
+	return reservation.Path[len(reservation.Path)-1].Middle.Id()
+}
+
+// Reservations locks paths of a topology, so that no two active reservations
+// overlap. It is safe for concurrent use.
+type Reservations[O, C, P comparable] struct {
+	mutex  sync.Mutex
+	top    *Topology[O, C, P]
+	mode   OverlapMode
+	nextId ReservationId
+	active map[ReservationId]*Reservation[O, P]
+}
+
+func NewReservations[O, C, P comparable](top *Topology[O, C, P], mode OverlapMode) *Reservations[O, C, P] {
+	return &Reservations[O, C, P]{
+		top:    top,
+		mode:   mode,
+		nextId: 1,
+		active: map[ReservationId]*Reservation[O, P]{},
+	}
+}
+
+// Reserve reserves a path unless it overlaps with an active reservation.
+func (reservations *Reservations[O, C, P]) Reserve(path []*graphs.PathSegment[O, P]) (*Reservation[O, P], error) {
+	if len(path) == 0 {
+		return nil, errors.New("cannot reserve empty path")
+	}
+
+	reservations.mutex.Lock()
+	defer reservations.mutex.Unlock()
+
+	return reservations.reserve(path)
+}
+
+// ReserveRef reserves the first path between two objects, as found by
+// Topology.FindRef, that does not overlap with an active reservation.
+func (reservations *Reservations[O, C, P]) ReserveRef(fromRef, toRef O) (*Reservation[O, P], error) {
+	paths, err := reservations.top.FindRef(fromRef, toRef)
+	if err != nil {
+		return nil, err
+	}
+
+	// Candidates are searched before locking, so that concurrent reservations
+	// only wait for the overlap checks rather than the search.
+	reservations.mutex.Lock()
+	defer reservations.mutex.Unlock()
+
+	var conflict error
+	for _, path := range paths {
+		reservation, err := reservations.reserve(path)
+		if err == nil {
+			return reservation, nil
+		}
+
+		conflict = err
+	}
+
+	if conflict != nil {
+		return nil, conflict
+	}
+
+	return nil, fmt.Errorf("no path from %v to %v found to reserve", fromRef, toRef)
+}
+
+func (reservations *Reservations[O, C, P]) reserve(path []*graphs.PathSegment[O, P]) (*Reservation[O, P], error) {
+	candidate := newReservation(path)
+
+	for _, id := range reservations.ids() {
+		if reservations.active[id].overlaps(candidate, reservations.mode) {
+			return nil, fmt.Errorf("%w: path from %v to %v overlaps with reservation %d", ErrReservationConflict, candidate.From(), candidate.To(), id)
+		}
+	}
+
+	candidate.Id = reservations.nextId
+	reservations.nextId++
+	reservations.active[candidate.Id] = candidate
+
+	return candidate, nil
+}
+
+func (reservations *Reservations[O, C, P]) Release(id ReservationId) error {
+	reservations.mutex.Lock()
+	defer reservations.mutex.Unlock()
+
+	if _, ok := reservations.active[id]; !ok {
+		return fmt.Errorf("reservation %d not found and thus cannot be released", id)
+	}
+
+	delete(reservations.active, id)
+	return nil
+}
+
+// Active lists all active reservations ordered by their IDs.
+func (reservations *Reservations[O, C, P]) Active() []*Reservation[O, P] {
+	reservations.mutex.Lock()
+	defer reservations.mutex.Unlock()
+
+	active := []*Reservation[O, P]{}
+	for _, id := range reservations.ids() {
+		active = append(active, reservations.active[id])
+	}
+
+	return active
+}
+
+func (reservations *Reservations[O, C, P]) ids() []ReservationId {
+	ids := []ReservationId{}
+	for id := range reservations.active {
+		ids = append(ids, id)
+	}
+
+	slices.Sort(ids)
+	return ids
+}
+
+func newReservation[O, P comparable](path []*graphs.PathSegment[O, P]) *Reservation[O, P] {
+	reservation := &Reservation[O, P]{
+		Path:  path,
+		nodes: map[O]bool{},
+		ports: map[graphs.PortRef[O, P]]bool{},
+	}
+
+	for node, port := range pathPorts(path) {
+		reservation.nodes[node] = true
+		reservation.ports[graphs.PortRef[O, P]{Node: node, Port: port}] = true
+	}
+
+	for _, segment := range path {
+		reservation.nodes[segment.Middle.Id()] = true
+	}
+
+	return reservation
+}
+
+func (reservation *Reservation[O, P]) overlaps(o *Reservation[O, P], mode OverlapMode) bool {
+	if mode == PortExclusive {
+		for port := range o.ports {
+			if reservation.ports[port] {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for node := range o.nodes {
+		if reservation.nodes[node] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// pathPorts yields every port a path uses. The first segment's entry is not
+// used, as paths start by leaving their first node.
+func pathPorts[O, P comparable](path []*graphs.PathSegment[O, P]) iter.Seq2[O, P] {
+	return func(yield func(O, P) bool) {
+		for i, segment := range path {
+			if i > 0 && segment.Left.IsSome() && !yield(segment.Middle.Id(), segment.Left.Unwrap()) {
+				return
+			}
+
+			if segment.Right.IsSome() && !yield(segment.Middle.Id(), segment.Right.Unwrap()) {
+				return
+			}
+		}
+	}
+}
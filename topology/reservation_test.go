@@ -0,0 +1,104 @@
+package topology_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/moznion/go-optional"
+	"github.com/yannickkirschen/graphs"
+	"github.com/yannickkirschen/graphs/topology"
+)
+
+func TestReserveNodeExclusive(t *testing.T) {
+	top := MakeTopology(t)
+	reservations := topology.NewReservations(top, topology.NodeExclusive)
+
+	first, err := reservations.ReserveRef("S1", "S2")
+	if err != nil {
+		t.Fatalf("error when reserving route: %s", err)
+	}
+
+	if first.From() != "S1" || first.To() != "S2" {
+		t.Fatalf("expected reservation from S1 to S2, but got %v to %v", first.From(), first.To())
+	}
+
+	if _, err := reservations.ReserveRef("S1", "S3"); !errors.Is(err, topology.ErrReservationConflict) {
+		t.Fatalf("expected conflict when reserving route sharing S1 and W1, but got %v", err)
+	}
+
+	if err := reservations.Release(first.Id); err != nil {
+		t.Fatalf("error when releasing reservation: %s", err)
+	}
+
+	if err := reservations.Release(first.Id); err == nil {
+		t.Fatalf("expected error when releasing reservation twice")
+	}
+
+	second, err := reservations.ReserveRef("S1", "S3")
+	if err != nil {
+		t.Fatalf("error when reserving route after release: %s", err)
+	}
+
+	if active := reservations.Active(); len(active) != 1 || active[0].Id != second.Id || second.Id == first.Id {
+		t.Fatalf("expected only reservation %d to be active, but got %v", second.Id, active)
+	}
+}
+
+func TestReservePortExclusive(t *testing.T) {
+	top := MakeTopology(t)
+	reservations := topology.NewReservations(top, topology.PortExclusive)
+
+	if _, err := reservations.ReserveRef("S1", "S2"); err != nil {
+		t.Fatalf("error when reserving route: %s", err)
+	}
+
+	point := top.Graph().Node("W1").Unwrap()
+	signal := top.Graph().Node("S2").Unwrap()
+
+	diversion := []*graphs.PathSegment[string, string]{{Left: optional.None[string](), Middle: point, Right: optional.Some("diversion")}}
+	if _, err := reservations.Reserve(diversion); err != nil {
+		t.Fatalf("expected unused port of W1 to be reservable, but got %s", err)
+	}
+
+	main := []*graphs.PathSegment[string, string]{{Left: optional.Some("b"), Middle: signal, Right: optional.Some("a")}}
+	if _, err := reservations.Reserve(main); !errors.Is(err, topology.ErrReservationConflict) {
+		t.Fatalf("expected conflict when reserving used port of S2, but got %v", err)
+	}
+
+	nodes := topology.NewReservations(top, topology.NodeExclusive)
+	if _, err := nodes.ReserveRef("S1", "S2"); err != nil {
+		t.Fatalf("error when reserving route: %s", err)
+	}
+
+	if _, err := nodes.Reserve(diversion); !errors.Is(err, topology.ErrReservationConflict) {
+		t.Fatalf("expected conflict when reserving W1 exclusively, but got %v", err)
+	}
+}
+
+func TestReserveConcurrently(t *testing.T) {
+	top := MakeTopology(t)
+	reservations := topology.NewReservations(top, topology.NodeExclusive)
+
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	succeeded := 0
+
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := reservations.ReserveRef("S1", "S2"); err == nil {
+				mutex.Lock()
+				succeeded++
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Fatalf("expected exactly 1 reservation to succeed, but got %d", succeeded)
+	}
+}
@@ -0,0 +1,153 @@
+package topology
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/yannickkirschen/graphs"
+	"github.com/yannickkirschen/graphs/inventory"
+)
+
+// Route is a path between an object that allows paths to start and an object
+// that allows paths to end.
+type Route[O, C, P comparable] struct {
+	Id   string
+	From *inventory.Object[O, C, P]
+	To   *inventory.Object[O, C, P]
+	Path []*graphs.PathSegment[O, P]
+}
+
+// Routes finds all routes between objects whose class' path construction has a
+// start and objects whose class' path construction has an end. Routes are
+// ordered by their start and end objects in inventory order. IDs consist of
+// both objects and a hash of the objects and ports passed, like S1-S2.4f3c2a1b,
+// so they stay the same as long as the route does. Occupied objects are not
+// taken into account, so the routes are the same regardless of occupancy.
+func (top *Topology[O, C, P]) Routes() ([]*Route[O, C, P], error) {
+	froms := []*inventory.Object[O, C, P]{}
+	tos := []*inventory.Object[O, C, P]{}
+
+	for _, object := range top.inv.Objects() {
+		if object.Class.PathConstruction == nil {
+			continue
+		}
+
+		if object.Class.PathConstruction.Start != nil {
+			froms = append(froms, object)
+		}
+
+		if object.Class.PathConstruction.End != nil {
+			tos = append(tos, object)
+		}
+	}
+
+	return top.routes(froms, tos, graphs.FindOptions[O, P]{IgnoreOccupancy: true})
+}
+
+// FindMulti finds all routes from any of the objects in fromRefs to any of the
+// objects in toRefs. There is a single search per start object rather than one
+// per pair. The catalog groups the routes by their start and end objects.
+func (top *Topology[O, C, P]) FindMulti(fromRefs, toRefs []O) (*RouteCatalog[O, C, P], error) {
+	froms := make([]*inventory.Object[O, C, P], len(fromRefs))
+	for i, ref := range fromRefs {
+		from, err := top.start(ref)
+		if err != nil {
+			return nil, err
+		}
+		froms[i] = from
+	}
+
+	tos := make([]*inventory.Object[O, C, P], len(toRefs))
+	for i, ref := range toRefs {
+		to, err := top.end(ref)
+		if err != nil {
+			return nil, err
+		}
+		tos[i] = to
+	}
+
+	routes, err := top.routes(froms, tos, graphs.FindOptions[O, P]{})
+	if err != nil {
+		return nil, err
+	}
+
+	return newRouteCatalog(routes), nil
+}
+
+// routes finds the routes between all pairs of distinct objects.
+func (top *Topology[O, C, P]) routes(froms, tos []*inventory.Object[O, C, P], options graphs.FindOptions[O, P]) ([]*Route[O, C, P], error) {
+	sources := make([]graphs.PortRef[O, P], len(froms))
+	for i, from := range froms {
+		sources[i] = graphs.PortRef[O, P]{Node: from.Id, Port: from.Class.PathConstruction.Start.Id}
+	}
+
+	targets := make([]graphs.PortRef[O, P], len(tos))
+	for i, to := range tos {
+		targets[i] = graphs.PortRef[O, P]{Node: to.Id, Port: to.Class.PathConstruction.End.Id}
+	}
+
+	groups, _, err := top.graph.FindMultiContext(context.Background(), sources, targets, options)
+	if err != nil {
+		return nil, err
+	}
+
+	routes := []*Route[O, C, P]{}
+	ids := map[string]bool{}
+
+	for i, group := range groups {
+		from, to := froms[i/len(tos)], tos[i%len(tos)]
+		if from.Id == to.Id {
+			continue
+		}
+
+		for _, path := range group.Paths {
+			id := routeId(from.Id, to.Id, path)
+			if ids[id] {
+				return nil, fmt.Errorf("route ID %s is not unique as two routes from %v to %v hash to it", id, from.Id, to.Id)
+			}
+
+			ids[id] = true
+			routes = append(routes, &Route[O, C, P]{id, from, to, path})
+		}
+	}
+
+	return routes, nil
+}
+
+// routeId derives the ID of a route from the objects and ports it passes rather
+// than the order in which it was found. Finding routes fails in the unlikely case
+// of two routes with the same ID.
+func routeId[O, P comparable](from, to O, path []*graphs.PathSegment[O, P]) string {
+	hash := fnv.New64a()
+	for node, port := range pathPorts(path) {
+		fmt.Fprintf(hash, "%v\x00%v\x00", node, port)
+	}
+
+	return fmt.Sprintf("%v-%v.%016x", from, to, hash.Sum64())
+}
+
+// BlockedRoutes returns all routes that currently pass an occupied object or
+// use an occupied port.
+func (top *Topology[O, C, P]) BlockedRoutes() ([]*Route[O, C, P], error) {
+	return top.BlockedRoutesWith(top.graph.Occupancy())
+}
+
+// BlockedRoutesWith works like BlockedRoutes, but checks the routes against
+// another occupancy, like the one of a ConcurrentGraph.
+func (top *Topology[O, C, P]) BlockedRoutesWith(occupancy *graphs.Occupancy[O, P]) ([]*Route[O, C, P], error) {
+	routes, err := top.Routes()
+	if err != nil {
+		return nil, err
+	}
+
+	blocked := []*Route[O, C, P]{}
+
+	for _, route := range routes {
+		if occupancy.Blocks(route.Path) {
+			blocked = append(blocked, route)
+		}
+	}
+
+	return blocked, nil
+}
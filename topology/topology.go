@@ -1,7 +1,9 @@
 package topology
 
 import (
+	"context"
 	"fmt"
+	"iter"
 
 	"github.com/yannickkirschen/graphs"
 	"github.com/yannickkirschen/graphs/inventory"
@@ -16,27 +18,90 @@ func NewTopology[O, C, P comparable]() *Topology[O, C, P] {
 	return &Topology[O, C, P]{nil, nil}
 }
 
+func (top *Topology[O, C, P]) Inventory() *inventory.Inventory[O, C, P] {
+	return top.inv
+}
+
+func (top *Topology[O, C, P]) Graph() *graphs.Graph[O, P] {
+	return top.graph
+}
+
+// Occupancy marks objects and ports as occupied, so that FindRef and Paths skip
+// them.
+func (top *Topology[O, C, P]) Occupancy() *graphs.Occupancy[O, P] {
+	return top.graph.Occupancy()
+}
+
 func (top *Topology[O, C, P]) FindRef(fromRef, toRef O) ([][]*graphs.PathSegment[O, P], error) {
-	from, err := top.inv.GetObject(fromRef).Take()
+	from, fromPort, to, toPort, err := top.endpoints(fromRef, toRef)
 	if err != nil {
-		return nil, fmt.Errorf("from ref %v not found in inventory: %s", fromRef, err)
+		return nil, err
 	}
 
-	to, err := top.inv.GetObject(toRef).Take()
+	return top.graph.FindRef(from, fromPort, to, toPort)
+}
+
+func (top *Topology[O, C, P]) FindRefContext(ctx context.Context, fromRef, toRef O, options graphs.FindOptions[O, P]) ([][]*graphs.PathSegment[O, P], graphs.FindStatus, error) {
+	from, fromPort, to, toPort, err := top.endpoints(fromRef, toRef)
 	if err != nil {
-		return nil, fmt.Errorf("to ref %v not found in inventory: %s", toRef, err)
+		return nil, graphs.FindExhausted, err
 	}
 
-	if from.Class.PathConstruction == nil || from.Class.PathConstruction.Start == nil {
-		return nil, fmt.Errorf("object %s (ID %v) does not allow paths to start here", from.Label, from.Id)
+	return top.graph.FindRefContext(ctx, from, fromPort, to, toPort, options)
+}
+
+func (top *Topology[O, C, P]) Paths(fromRef, toRef O) (iter.Seq[[]*graphs.PathSegment[O, P]], error) {
+	from, fromPort, to, toPort, err := top.endpoints(fromRef, toRef)
+	if err != nil {
+		return nil, err
 	}
 
-	if to.Class.PathConstruction == nil || to.Class.PathConstruction.End == nil {
-		return nil, fmt.Errorf("object %s (ID %v) does not allow paths to end here", to.Label, to.Id)
+	return top.graph.PathsRef(from, fromPort, to, toPort)
+}
+
+// endpoints resolves the ports at which paths between two objects start and
+// end according to their classes' path construction.
+func (top *Topology[O, C, P]) endpoints(fromRef, toRef O) (O, P, O, P, error) {
+	var noObject O
+	var noPort P
+
+	from, err := top.start(fromRef)
+	if err != nil {
+		return noObject, noPort, noObject, noPort, err
 	}
 
-	fromPort := from.Class.PathConstruction.Start
-	toPort := to.Class.PathConstruction.End
+	to, err := top.end(toRef)
+	if err != nil {
+		return noObject, noPort, noObject, noPort, err
+	}
+
+	return from.Id, from.Class.PathConstruction.Start.Id, to.Id, to.Class.PathConstruction.End.Id, nil
+}
+
+// start resolves an object that allows paths to start.
+func (top *Topology[O, C, P]) start(ref O) (*inventory.Object[O, C, P], error) {
+	object, err := top.inv.GetObject(ref).Take()
+	if err != nil {
+		return nil, fmt.Errorf("from ref %v not found in inventory: %s", ref, err)
+	}
+
+	if object.Class.PathConstruction == nil || object.Class.PathConstruction.Start == nil {
+		return nil, fmt.Errorf("object %s (ID %v) does not allow paths to start here", object.Label, object.Id)
+	}
+
+	return object, nil
+}
+
+// end resolves an object that allows paths to end.
+func (top *Topology[O, C, P]) end(ref O) (*inventory.Object[O, C, P], error) {
+	object, err := top.inv.GetObject(ref).Take()
+	if err != nil {
+		return nil, fmt.Errorf("to ref %v not found in inventory: %s", ref, err)
+	}
+
+	if object.Class.PathConstruction == nil || object.Class.PathConstruction.End == nil {
+		return nil, fmt.Errorf("object %s (ID %v) does not allow paths to end here", object.Label, object.Id)
+	}
 
-	return top.graph.FindRef(from.Id, fromPort.Id, to.Id, toPort.Id)
+	return object, nil
 }
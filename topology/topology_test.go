@@ -0,0 +1,382 @@
+package topology_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/yannickkirschen/graphs"
+	"github.com/yannickkirschen/graphs/inventory"
+	"github.com/yannickkirschen/graphs/topology"
+)
+
+/*
+   The tested topology is a small station:
+
+             ,- main ------ S2
+   S1 --- W1
+             `- diversion - S3 --- B1
+*/
+
+func MakeTopology(t *testing.T) *topology.Topology[string, string, string] {
+	inv, err := inventory.ParseFile[string, string, string]("testdata/inventory.yaml")
+	if err != nil {
+		t.Fatalf("error when parsing inventory: %s", err)
+	}
+
+	top, err := topology.ParseFile(inv, "testdata/topology.yaml")
+	if err != nil {
+		t.Fatalf("error when parsing topology: %s", err)
+	}
+
+	return top
+}
+
+func TestRoutes(t *testing.T) {
+	top := MakeTopology(t)
+
+	routes, err := top.Routes()
+	if err != nil {
+		t.Fatalf("error when finding routes: %s", err)
+	}
+
+	if len(routes) != 2 {
+		t.Fatalf("expected 2 routes, but got %d: %v", len(routes), routes)
+	}
+
+	if routes[0].Id != "S1-S2.e57f975e4e7f404b" || routes[1].Id != "S1-S3.38302fc775fd8843" {
+		t.Fatalf("expected routes S1-S2.e57f975e4e7f404b and S1-S3.38302fc775fd8843, but got %s and %s", routes[0].Id, routes[1].Id)
+	}
+}
+
+func TestRouteIdsStable(t *testing.T) {
+	top := MakeTopology(t)
+	top.Graph().ConnectRef("S3", "b", "S2", "a")
+
+	routes, err := top.Routes()
+	if err != nil {
+		t.Fatalf("error when finding routes: %s", err)
+	}
+
+	if len(routes) != 4 || routes[1].From.Id != "S1" || routes[1].To.Id != "S2" || routes[1].Id == routes[0].Id {
+		t.Fatalf("expected 2 distinct routes from S1 to S2, but got %v", routes)
+	}
+
+	top.Graph().DisconnectRefBi("W1", "main", "S2", "a")
+
+	remaining, err := top.Routes()
+	if err != nil {
+		t.Fatalf("error when finding routes: %s", err)
+	}
+
+	if len(remaining) != 3 || remaining[0].Id != routes[1].Id {
+		t.Fatalf("expected route %s via S3 to keep its ID, but got %v", routes[1].Id, remaining)
+	}
+}
+
+func TestWriteInterlockingCSV(t *testing.T) {
+	top := MakeTopology(t)
+
+	var builder strings.Builder
+	if err := top.WriteInterlockingCSV(&builder); err != nil {
+		t.Fatalf("error when writing interlocking table: %s", err)
+	}
+
+	expected := `Route,From,To,Step,Object,Label,Class,Entry,Exit,State
+S1-S2.e57f975e4e7f404b,S1,S2,1,S1,Signal 1,Signal,,b,
+S1-S2.e57f975e4e7f404b,S1,S2,2,W1,Point 1,Point,head,main,straight
+S1-S2.e57f975e4e7f404b,S1,S2,3,S2,Signal 2,Signal,a,b,
+S1-S3.38302fc775fd8843,S1,S3,1,S1,Signal 1,Signal,,b,
+S1-S3.38302fc775fd8843,S1,S3,2,W1,Point 1,Point,head,diversion,diverging
+S1-S3.38302fc775fd8843,S1,S3,3,S3,Signal 3,Signal,a,b,
+`
+
+	if builder.String() != expected {
+		t.Fatalf("expected\n%s\nbut got\n%s", expected, builder.String())
+	}
+}
+
+func TestWriteInterlockingMarkdown(t *testing.T) {
+	top := MakeTopology(t)
+
+	var builder strings.Builder
+	if err := top.WriteInterlockingMarkdown(&builder); err != nil {
+		t.Fatalf("error when writing interlocking table: %s", err)
+	}
+
+	expected := `# Interlocking table
+
+## Route S1-S2.e57f975e4e7f404b: Signal 1 → Signal 2
+
+| Step | Object | Label | Class | Entry | Exit | State |
+| ---: | --- | --- | --- | --- | --- | --- |
+| 1 | S1 | Signal 1 | Signal |  | b |  |
+| 2 | W1 | Point 1 | Point | head | main | straight |
+| 3 | S2 | Signal 2 | Signal | a | b |  |
+`
+
+	if !strings.HasPrefix(builder.String(), expected) {
+		t.Fatalf("expected output to start with\n%s\nbut got\n%s", expected, builder.String())
+	}
+}
+
+func TestConflictMatrix(t *testing.T) {
+	top := MakeTopology(t)
+
+	matrix, err := top.ConflictMatrix()
+	if err != nil {
+		t.Fatalf("error when computing conflict matrix: %s", err)
+	}
+
+	if len(matrix.Routes) != 2 {
+		t.Fatalf("expected 2 routes, but got %v", matrix.Routes)
+	}
+
+	conflict := matrix.Conflicts[0][1]
+	if !conflict.Has(topology.SharedObject) || !conflict.Has(topology.IncompatibleState) {
+		t.Fatalf("expected routes to share objects and require different states of W1, but got %q", conflict)
+	}
+
+	if matrix.Conflicts[0][0] != 0 {
+		t.Fatalf("expected route not to conflict with itself, but got %q", matrix.Conflicts[0][0])
+	}
+
+	var builder strings.Builder
+	if err := matrix.WriteCSV(&builder); err != nil {
+		t.Fatalf("error when writing conflict matrix: %s", err)
+	}
+
+	expected := `,S1-S2.e57f975e4e7f404b,S1-S3.38302fc775fd8843
+S1-S2.e57f975e4e7f404b,,object+state
+S1-S3.38302fc775fd8843,object+state,
+`
+
+	if builder.String() != expected {
+		t.Fatalf("expected\n%s\nbut got\n%s", expected, builder.String())
+	}
+}
+
+func TestRouteCatalog(t *testing.T) {
+	top := MakeTopology(t)
+
+	catalog, err := top.RouteCatalog()
+	if err != nil {
+		t.Fatalf("error when computing route catalog: %s", err)
+	}
+
+	if routes := catalog.Get("S1", "S3"); len(routes) != 1 || routes[0].Id != "S1-S3.38302fc775fd8843" {
+		t.Fatalf("expected route S1-S3.38302fc775fd8843, but got %v", routes)
+	}
+
+	if routes := catalog.Get("S2", "S1"); len(routes) != 0 {
+		t.Fatalf("expected no routes from S2 to S1, but got %v", routes)
+	}
+
+	if keys := catalog.Keys(); len(keys) != 2 || keys[0].To != "S2" || keys[1].To != "S3" {
+		t.Fatalf("expected keys S1-S2 and S1-S3, but got %v", keys)
+	}
+
+	var builder strings.Builder
+	if err := catalog.Write(&builder); err != nil {
+		t.Fatalf("error when writing route catalog: %s", err)
+	}
+
+	expected := `routes:
+  - id: S1-S2.e57f975e4e7f404b
+    from: S1
+    to: S2
+    steps:
+      - object: S1
+        exit: b
+      - object: W1
+        entry: head
+        exit: main
+        state: straight
+      - object: S2
+        entry: a
+        exit: b
+`
+
+	if !strings.HasPrefix(builder.String(), expected) {
+		t.Fatalf("expected catalog to start with\n%s\nbut got\n%s", expected, builder.String())
+	}
+}
+
+func TestBlockedRoutes(t *testing.T) {
+	top := MakeTopology(t)
+	top.Occupancy().OccupyNode("S3")
+
+	blocked, err := top.BlockedRoutes()
+	if err != nil {
+		t.Fatalf("error when finding blocked routes: %s", err)
+	}
+
+	if len(blocked) != 1 || blocked[0].Id != "S1-S3.38302fc775fd8843" {
+		t.Fatalf("expected route S1-S3.38302fc775fd8843 to be blocked, but got %v", blocked)
+	}
+
+	paths, err := top.FindRef("S1", "S3")
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 0 {
+		t.Fatalf("expected no paths to occupied S3, but got %v", paths)
+	}
+}
+
+func TestBlockedRoutesWith(t *testing.T) {
+	top := MakeTopology(t)
+
+	occupancy := graphs.NewOccupancy[string, string]()
+	occupancy.OccupyPort("W1", "main")
+
+	blocked, err := top.BlockedRoutesWith(occupancy)
+	if err != nil {
+		t.Fatalf("error when finding blocked routes: %s", err)
+	}
+
+	if len(blocked) != 1 || blocked[0].Id != "S1-S2.e57f975e4e7f404b" {
+		t.Fatalf("expected route S1-S2.e57f975e4e7f404b to be blocked, but got %v", blocked)
+	}
+
+	if blocked, _ := top.BlockedRoutes(); len(blocked) != 0 {
+		t.Fatalf("expected the topology's own occupancy to block no routes, but got %v", blocked)
+	}
+}
+
+func TestFindRefConstraints(t *testing.T) {
+	top := MakeTopology(t)
+
+	options := graphs.FindOptions[string, string]{Constraints: graphs.Constraints[string, string]{Via: []string{"W1"}}}
+	paths, _, err := top.FindRefContext(context.Background(), "S1", "S3", options)
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 1 {
+		t.Fatalf("expected 1 path via W1, but got %d: %v", len(paths), paths)
+	}
+
+	options.Constraints = graphs.Constraints[string, string]{AvoidPorts: []graphs.PortRef[string, string]{{Node: "W1", Port: "diversion"}}}
+	paths, _, err = top.FindRefContext(context.Background(), "S1", "S3", options)
+	if err != nil {
+		t.Fatalf("error when finding paths: %s", err)
+	}
+
+	if len(paths) != 0 {
+		t.Fatalf("expected no paths avoiding W1.diversion, but got %v", paths)
+	}
+}
+
+func TestNearestOfClass(t *testing.T) {
+	top := MakeTopology(t)
+
+	nearest, err := top.NearestOfClass("S1", "signal", 0, nil)
+	if err != nil {
+		t.Fatalf("error when finding nearest objects: %s", err)
+	}
+
+	if len(nearest) != 2 || nearest[0].Object.Id != "S2" || nearest[1].Object.Id != "S3" {
+		t.Fatalf("expected S2 and S3, but got %v", nearest)
+	}
+
+	if nearest[0].Cost != 2 || nearest[1].Cost != 4 {
+		t.Fatalf("expected costs 2 and 4, but got %v and %v", nearest[0].Cost, nearest[1].Cost)
+	}
+
+	nearest, err = top.NearestOfClass("S1", "buffer", 1, nil)
+	if err != nil {
+		t.Fatalf("error when finding nearest objects: %s", err)
+	}
+
+	if len(nearest) != 1 || nearest[0].Object.Id != "B1" || nearest[0].Hops != 3 {
+		t.Fatalf("expected B1 after 3 hops, but got %v", nearest)
+	}
+}
+
+func TestFindMulti(t *testing.T) {
+	top := MakeTopology(t)
+
+	catalog, err := top.FindMulti([]string{"S1"}, []string{"S2", "S3"})
+	if err != nil {
+		t.Fatalf("error when finding routes: %s", err)
+	}
+
+	if len(catalog.Get("S1", "S2")) != 1 || len(catalog.Get("S1", "S3")) != 1 {
+		t.Fatalf("expected one route to S2 and one to S3, but got %v", catalog.Keys())
+	}
+
+	if _, ok := catalog.Route("S1-S3.38302fc775fd8843"); !ok {
+		t.Fatalf("expected route S1-S3.38302fc775fd8843 in catalog")
+	}
+
+	if _, err := top.FindMulti([]string{"B1"}, []string{"S2"}); err == nil {
+		t.Fatalf("expected error when starting at B1")
+	}
+}
+
+func TestWriteMermaid(t *testing.T) {
+	top := MakeTopology(t)
+
+	var builder strings.Builder
+	if err := top.WriteMermaid(&builder, &topology.MermaidOptions{Subgraphs: topology.ClassSubgraphs}); err != nil {
+		t.Fatalf("error when writing Mermaid: %s", err)
+	}
+
+	expected := `flowchart LR
+	subgraph g0["Signal"]
+		n0["Signal 1"]
+		n2["Signal 2"]
+		n3["Signal 3"]
+	end
+	subgraph g1["Point"]
+		n1["Point 1"]
+	end
+	subgraph g2["Buffer"]
+		n4["Buffer 1"]
+	end
+	n0 ---|"B - Head"| n1
+	n1 ---|"Main - A"| n2
+	n1 ---|"Diversion - A"| n3
+	n3 ---|"B - A"| n4
+`
+
+	if builder.String() != expected {
+		t.Fatalf("unexpected Mermaid output:\n%s", builder.String())
+	}
+}
+
+func TestWriteMermaidClassesWithSameLabel(t *testing.T) {
+	top := MakeTopology(t)
+	top.Inventory().GetClass("buffer").Unwrap().Label = "Signal"
+
+	var builder strings.Builder
+	if err := top.WriteMermaid(&builder, &topology.MermaidOptions{Subgraphs: topology.ClassSubgraphs}); err != nil {
+		t.Fatalf("error when writing Mermaid: %s", err)
+	}
+
+	if !strings.Contains(builder.String(), "\tsubgraph g2[\"Signal\"]\n\t\tn4[\"Buffer 1\"]\n\tend\n") {
+		t.Fatalf("expected buffer to have a subgraph of its own, but got:\n%s", builder.String())
+	}
+}
+
+func TestWriteMermaidObjectSubgraphs(t *testing.T) {
+	top := MakeTopology(t)
+
+	var builder strings.Builder
+	if err := top.WriteMermaid(&builder, &topology.MermaidOptions{Subgraphs: topology.ObjectSubgraphs}); err != nil {
+		t.Fatalf("error when writing Mermaid: %s", err)
+	}
+
+	for _, line := range []string{
+		"\tsubgraph n1[\"Point 1\"]\n",
+		"\t\tn1p0([\"Head\"])\n",
+		"\t\tn1p0 --- n1p1\n",
+		"\tn0p1 --- n1p0\n",
+	} {
+		if !strings.Contains(builder.String(), line) {
+			t.Fatalf("expected Mermaid output to contain %q, but got:\n%s", line, builder.String())
+		}
+	}
+}
@@ -1,7 +0,0 @@
-package graphs
-
-type Triple[L, M, R any] struct {
-	Left   L
-	Middle M
-	Right  R
-}